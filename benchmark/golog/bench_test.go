@@ -196,6 +196,37 @@ func BenchmarkEventCtxWeak(b *testing.B) {
 	fmt.Println("=========================================================")
 }
 
+// BenchmarkEventStruct tests the performance of logging an event with
+// struct-heavy fields, which exercises the encoders cached per type.
+func BenchmarkEventStruct(b *testing.B) {
+	b.Logf("Log an event with nested structs, slices of structs and pointers")
+
+	for _, v := range loggers {
+		b.Run(v.name(), func(b *testing.B) {
+			out := &blackhole{}
+			l := v.new(out)
+
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					l.logEventStruct(logMsg)
+				}
+			})
+
+			if out.WriteCount() != uint64(b.N) {
+				b.Fatalf(
+					"Mismatch in log write count. Expected: %d, Actual: %d",
+					b.N,
+					out.WriteCount(),
+				)
+			}
+		})
+	}
+
+	fmt.Println("=========================================================")
+}
+
 // BenchmarkDisabledCtxWeak tests the impact of logging at a disabled level
 // with weakly typed contextual fields.
 func BenchmarkDisabledCtxWeak(b *testing.B) {
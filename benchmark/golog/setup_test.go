@@ -15,6 +15,30 @@ type user struct {
 
 type users []user
 
+// order is a struct-heavy value with nested structs, slices of structs,
+// pointers and tagged fields, as logged by a typical service.
+type order struct {
+	ID       int64       `json:"id"`
+	Customer *user       `json:"customer"`
+	Items    []orderItem `json:"items"`
+	Shipping address     `json:"shipping"`
+	Tags     []string    `json:"tags,omitempty"`
+	Note     string      `json:"note,omitempty"`
+	Paid     bool        `json:"paid"`
+}
+
+type orderItem struct {
+	SKU      string  `json:"sku"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type address struct {
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
 var (
 	ctxBodyBytes     = 123456789
 	ctxRequest       = "GET /icons/ubuntu-logo.png HTTP/1.1"
@@ -36,6 +60,18 @@ var (
 		ctxUser,
 		ctxUser,
 	}
+	ctxOrder = &order{
+		ID:       42,
+		Customer: &ctxUser,
+		Items: []orderItem{
+			{SKU: "A-100", Quantity: 2, Price: 9.99},
+			{SKU: "B-200", Quantity: 1, Price: 24.5},
+			{SKU: "C-300", Quantity: 5, Price: 1.25},
+		},
+		Shipping: address{Street: "1 Main St", City: "Springfield", Country: "US"},
+		Tags:     []string{"gift", "express"},
+		Paid:     true,
+	}
 	ctxTime   = time.Now()
 	ctxMonths = []string{
 		"January",
@@ -121,6 +157,7 @@ type logBenchmark interface {
 	logEventFmt(msg string, args ...any)
 	logEventCtx(msg string)
 	logEventCtxWeak(msg string)
+	logEventStruct(msg string)
 	logDisabled(msg string)
 	logDisabledFmt(msg string, args ...any)
 	logDisabledCtx(msg string)
@@ -60,6 +60,17 @@ func (b *slogBench) logEventCtxWeak(msg string) {
 	b.l.Info(msg, alternatingKeyValuePairs()...)
 }
 
+func (b *slogBench) logEventStruct(msg string) {
+	b.l.LogAttrs(
+		context.Background(),
+		slog.LevelInfo,
+		msg,
+		slog.Any("order", ctxOrder),
+		slog.Any("user", ctxUser),
+		slog.Any("users", ctxUsers),
+	)
+}
+
 func (b *slogBench) logDisabled(msg string) {
 	b.l.Debug(msg)
 }
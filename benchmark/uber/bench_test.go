@@ -258,3 +258,45 @@ func BenchmarkAddingFields(b *testing.B) {
 
 	fmt.Println("=========================================================")
 }
+
+func BenchmarkAddingStructFields(b *testing.B) {
+	b.Logf("Logging struct-heavy values at each log site.")
+	b.Run("slog", func(b *testing.B) {
+		logger := newSlogText()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info(getMessage(0), fakeSlogStructArgs()...)
+			}
+		})
+	})
+	b.Run("custom slog", func(b *testing.B) {
+		logger := newCustomSlogText()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info(getMessage(0), fakeSlogStructArgs()...)
+			}
+		})
+	})
+	b.Run("slog.LogAttrs", func(b *testing.B) {
+		logger := newSlogText()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.LogAttrs(context.Background(), slog.LevelInfo, getMessage(0), fakeSlogStructFields()...)
+			}
+		})
+	})
+	b.Run("custom slog.LogAttrs", func(b *testing.B) {
+		logger := newCustomSlogText()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.LogAttrs(context.Background(), slog.LevelInfo, getMessage(0), fakeSlogStructFields()...)
+			}
+		})
+	})
+
+	fmt.Println("=========================================================")
+}
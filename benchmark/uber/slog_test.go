@@ -52,3 +52,19 @@ func fakeSlogArgs() []any {
 		"error", errExample,
 	}
 }
+
+func fakeSlogStructFields() []slog.Attr {
+	return []slog.Attr{
+		slog.Any("order", _oneOrder),
+		slog.Any("user", _oneUser),
+		slog.Any("users", _tenUsers),
+	}
+}
+
+func fakeSlogStructArgs() []any {
+	return []any{
+		"order", _oneOrder,
+		"user", _oneUser,
+		"users", _tenUsers,
+	}
+}
@@ -0,0 +1,162 @@
+package slogja
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BytesFormat controls how []byte and [N]byte values are written.
+type BytesFormat int
+
+const (
+	// BytesAuto writes the bytes as a string when they are printable text,
+	// valid UTF-8 without control characters other than tab and newline,
+	// and as hex otherwise.
+	BytesAuto BytesFormat = iota
+	// BytesHex writes the bytes as lowercase hex.
+	BytesHex
+	// BytesBase64 writes the bytes in standard base64.
+	BytesBase64
+	// BytesHexdump writes the bytes in the format of hex.Dump, as a string
+	// in the JSON handler. The text handler writes a placeholder such as
+	// <5 bytes> in the line and the dump in an indented block below it,
+	// like the details of an error; bytes nested in another value are
+	// written there as hex. At most HandlerOptions.BytesDumpLimit bytes
+	// are dumped.
+	BytesHexdump
+)
+
+// defaultBytesDumpLimit is the number of bytes dumped by BytesHexdump when
+// HandlerOptions.BytesDumpLimit is zero.
+const defaultBytesDumpLimit = 256
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// isBytes reports whether t is a []byte or [N]byte, including named types
+// such as json.RawMessage.
+func isBytes(t reflect.Type) bool {
+	k := t.Kind()
+	return (k == reflect.Slice || k == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+// bytesOf returns the content of a []byte or [N]byte value. Arrays that are
+// not addressable are copied.
+func bytesOf(val reflect.Value) []byte {
+	if val.Kind() == reflect.Slice || val.CanAddr() {
+		return val.Bytes()
+	}
+	b := make([]byte, val.Len())
+	reflect.Copy(reflect.ValueOf(b), val)
+	return b
+}
+
+// walkBytes writes a []byte or [N]byte in the BytesFormat. A
+// json.RawMessage is written as the JSON it holds.
+func (w *anyWalker) walkBytes(buf *buffer, val reflect.Value) {
+	e := w.e
+
+	if val.Kind() == reflect.Slice && val.IsNil() {
+		e.writeNil(buf)
+		return
+	}
+
+	b := bytesOf(val)
+	if val.Type() == rawMessageType && json.Valid(b) {
+		var compact bytes.Buffer
+		if json.Compact(&compact, b) == nil {
+			e.writeRawJSON(buf, compact.Bytes())
+			return
+		}
+	}
+
+	max := w.opt.MaxStringLength
+	switch w.opt.Bytes {
+	case BytesHex:
+		e.writeStringer(buf, truncateString(hex.EncodeToString(b), max))
+	case BytesBase64:
+		e.writeStringer(buf, truncateString(base64.StdEncoding.EncodeToString(b), max))
+	case BytesHexdump:
+		e.writeDump(buf, b)
+	default:
+		if isText(b) {
+			e.writeString(buf, truncateString(string(b), max))
+		} else {
+			e.writeStringer(buf, truncateString(hex.EncodeToString(b), max))
+		}
+	}
+}
+
+// isText reports whether b is printable text that BytesAuto writes as a
+// string.
+func isText(b []byte) bool {
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size <= 1 {
+			return false
+		}
+		if r != '\t' && r != '\n' && !unicode.IsGraphic(r) {
+			return false
+		}
+		b = b[size:]
+	}
+	return true
+}
+
+// hexDump returns hex.Dump of at most BytesDumpLimit bytes of b, followed
+// by a marker line for the bytes left out.
+func hexDump(opts HandlerOptions, b []byte) string {
+	limit := opts.BytesDumpLimit
+	if limit <= 0 {
+		limit = defaultBytesDumpLimit
+	}
+	if len(b) <= limit {
+		return hex.Dump(b)
+	}
+	return string(appendMore([]byte(hex.Dump(b[:limit])), len(b)-limit)) + "\n"
+}
+
+// dumpBytes returns the bytes of val, the value of an attribute, if the
+// text handler dumps them below the line: with BytesHexdump, for a byte
+// slice or array whose type has no methods or renderer of its own.
+func (e *encodeText) dumpBytes(val slog.Value) ([]byte, bool) {
+	if e.opt.Bytes != BytesHexdump || val.Kind() != slog.KindAny {
+		return nil, false
+	}
+
+	v := reflect.ValueOf(val.Any())
+	if !v.IsValid() || !isBytes(v.Type()) || v.Type().NumMethod() > 0 || methodEncoder(v.Type()) != nil {
+		return nil, false
+	}
+	if _, ok := e.walk.renderers[v.Type()]; ok {
+		return nil, false
+	}
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return nil, false
+	}
+	return bytesOf(v), true
+}
+
+// writeDumpPlaceholder writes the placeholder left in the line for n bytes
+// dumped below it.
+func (e *encodeText) writeDumpPlaceholder(buf *buffer, n int) {
+	e.style(buf, e.theme.Separator)
+	e.writeStringer(buf, "<"+strconv.Itoa(n)+" bytes>")
+	e.endStyle(buf, e.theme.Separator)
+}
+
+// writeDumpLines writes the hex dump of b, each line indented and escaped.
+func (e *encodeText) writeDumpLines(buf *buffer, b []byte) {
+	for line := range strings.Lines(hexDump(e.opt, b)) {
+		e.writeIndent(buf, 2)
+		*buf = appendEscaped(*buf, strings.TrimSuffix(line, "\n"))
+		e.writeNewline(buf)
+	}
+}
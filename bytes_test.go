@@ -0,0 +1,148 @@
+package slogja
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteAnyBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		opt   HandlerOptions
+		value any
+		text  string
+		json  string
+	}{
+		{
+			name:  "should write valid UTF-8 as string",
+			value: []byte("héllo"),
+			text:  `"héllo"`,
+			json:  `"héllo"`,
+		},
+		{
+			name:  "should write invalid UTF-8 as hex",
+			value: []byte{0xff, 0x00, 0x10},
+			text:  `ff0010`,
+			json:  `"ff0010"`,
+		},
+		{
+			name:  "should write binary data that is valid UTF-8 as hex",
+			value: [4]byte{1},
+			text:  `01000000`,
+			json:  `"01000000"`,
+		},
+		{
+			name:  "should write text with tabs and newlines as string",
+			value: []byte("a\tb\n"),
+			text:  `"a\tb\n"`,
+			json:  `"a\tb\n"`,
+		},
+		{
+			name:  "should write array",
+			value: [3]byte{'a', 'b', 'c'},
+			text:  `"abc"`,
+			json:  `"abc"`,
+		},
+		{
+			name:  "should write nil slice",
+			value: []byte(nil),
+			text:  `nil`,
+			json:  `null`,
+		},
+		{
+			name:  "should write hex",
+			opt:   HandlerOptions{Bytes: BytesHex},
+			value: []byte("hi"),
+			text:  `6869`,
+			json:  `"6869"`,
+		},
+		{
+			name:  "should write base64",
+			opt:   HandlerOptions{Bytes: BytesBase64},
+			value: struct{ B [2]byte }{B: [2]byte{'h', 'i'}},
+			text:  `{B:"aGk="}`,
+			json:  `{"B":"aGk="}`,
+		},
+		{
+			name:  "should truncate with MaxStringLength",
+			opt:   HandlerOptions{Bytes: BytesHex, MaxStringLength: 4},
+			value: []byte("hello"),
+			text:  `"6865…(+6 more)"`,
+			json:  `"6865…(+6 more)"`,
+		},
+		{
+			name:  "should write hexdump, as hex when walked in text",
+			opt:   HandlerOptions{Bytes: BytesHexdump},
+			value: []byte("hello"),
+			text:  `68656c6c6f`,
+			json:  `"00000000  68 65 6c 6c 6f                                    |hello|\n"`,
+		},
+		{
+			name:  "should cap hexdump",
+			opt:   HandlerOptions{Bytes: BytesHexdump, BytesDumpLimit: 2},
+			value: []byte("hello"),
+			text:  `68656c6c6f`,
+			json:  `"00000000  68 65                                             |he|\n…(+3 more)\n"`,
+		},
+		{
+			name:  "should embed json.RawMessage",
+			value: map[string]any{"raw": json.RawMessage(`{ "a": [1, 2] }`)},
+			text:  `["raw":"{\"a\":[1,2]}"]`,
+			json:  `{"raw":{"a":[1,2]}}`,
+		},
+		{
+			name:  "should write invalid json.RawMessage as bytes",
+			value: json.RawMessage(`{`),
+			text:  `"{"`,
+			json:  `"{"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer()
+			defer buf.Free()
+
+			opt := tt.opt
+			opt.DisableColor = true
+			newEncodeText(opt).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.text {
+				t.Errorf("Expected text to contain '%s', got '%s'", tt.text, string(*buf))
+			}
+
+			*buf = (*buf)[:0]
+			newEncodeJSON(tt.opt).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.json {
+				t.Errorf("Expected json to contain '%s', got '%s'", tt.json, string(*buf))
+			}
+			if !json.Valid(*buf) {
+				t.Errorf("Expected valid json, got '%s'", string(*buf))
+			}
+		})
+	}
+}
+
+func TestHandlerBytes(t *testing.T) {
+	b := bytes.NewBuffer([]byte{})
+	l := slog.New(NewTextHandler(b, &HandlerOptions{
+		DisableColor: true,
+		DisableEmoji: true,
+		DisableTime:  true,
+		Bytes:        BytesHexdump,
+	}))
+
+	l.With("head", [2]byte{0xca, 0xfe}).Info("msg", "data", []byte("hello"), "nested", []any{[]byte("hi")}, "n", 1)
+
+	expected := "INF \"msg\" head=\"<2 bytes>\" data=\"<5 bytes>\" nested=[6869] n=1 \n" +
+		"  head:\n" +
+		"    00000000  ca fe" + strings.Repeat(" ", 45) + "|..|\n" +
+		"  data:\n" +
+		"    00000000  68 65 6c 6c 6f" + strings.Repeat(" ", 36) + "|hello|\n"
+	if b.String() != expected {
+		t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+	}
+}
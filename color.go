@@ -0,0 +1,85 @@
+package slogja
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode selects whether the text handler writes ANSI colors.
+// HandlerOptions.DisableColor turns colors off regardless of the mode.
+type ColorMode int
+
+const (
+	// ColorDefault writes colors to any writer unless the environment turns
+	// them off: FORCE_COLOR of "0" or "false", or NO_COLOR when FORCE_COLOR
+	// is not set.
+	ColorDefault ColorMode = iota
+	// ColorAlways writes colors to any writer, whatever the environment.
+	ColorAlways
+	// ColorNever never writes colors.
+	ColorNever
+	// ColorAuto writes colors when the environment asks for them or the
+	// writer is a terminal. FORCE_COLOR turns colors on, or off when it is
+	// "0" or "false"; otherwise NO_COLOR turns them off, CLICOLOR_FORCE
+	// turns them on and TERM=dumb turns them off.
+	ColorAuto
+)
+
+// useColor resolves the color mode of opts for w.
+func useColor(w io.Writer, opts HandlerOptions) bool {
+	if opts.DisableColor {
+		return false
+	}
+
+	switch opts.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	case ColorAuto:
+		detect := opts.IsTerminal
+		if detect == nil {
+			detect = isTerminal
+		}
+		return detectColor(w, detect)
+	default:
+		if on, ok := envColor(); ok {
+			return on
+		}
+		return true
+	}
+}
+
+// envColor returns the choice made by FORCE_COLOR or NO_COLOR, and false
+// for ok if neither is set.
+func envColor() (on, ok bool) {
+	if v := os.Getenv("FORCE_COLOR"); v != "" {
+		return v != "0" && v != "false", true
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false, true
+	}
+	return false, false
+}
+
+func detectColor(w io.Writer, detect func(w io.Writer) bool) bool {
+	if on, ok := envColor(); ok {
+		return on
+	}
+	if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return detect(w)
+}
+
+// isTerminal reports whether w is a file attached to a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isTerminalFile(f)
+}
@@ -0,0 +1,86 @@
+package slogja
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestUseColor(t *testing.T) {
+	terminal := func(w io.Writer) bool { return true }
+	notTerminal := func(w io.Writer) bool { return false }
+
+	tests := []struct {
+		name     string
+		opts     HandlerOptions
+		env      map[string]string
+		expected bool
+	}{
+		{name: "should use color by default", opts: HandlerOptions{}, expected: true},
+		{name: "should honour NO_COLOR by default", opts: HandlerOptions{}, env: map[string]string{"NO_COLOR": "1"}, expected: false},
+		{name: "should honour FORCE_COLOR by default", opts: HandlerOptions{}, env: map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, expected: true},
+		{name: "should disable color by default when FORCE_COLOR is 0", opts: HandlerOptions{}, env: map[string]string{"FORCE_COLOR": "0"}, expected: false},
+		{name: "should use color when always", opts: HandlerOptions{Color: ColorAlways}, expected: true},
+		{name: "should ignore NO_COLOR when always", opts: HandlerOptions{Color: ColorAlways}, env: map[string]string{"NO_COLOR": "1"}, expected: true},
+		{name: "should not use color when never", opts: HandlerOptions{Color: ColorNever}, env: map[string]string{"FORCE_COLOR": "1"}, expected: false},
+		{name: "should not use color when disabled", opts: HandlerOptions{Color: ColorAuto, DisableColor: true, IsTerminal: terminal}, expected: false},
+		{name: "should use color on terminal when auto", opts: HandlerOptions{Color: ColorAuto, IsTerminal: terminal}, expected: true},
+		{name: "should not use color off terminal when auto", opts: HandlerOptions{Color: ColorAuto, IsTerminal: notTerminal}, expected: false},
+		{name: "should honour NO_COLOR when auto", opts: HandlerOptions{Color: ColorAuto, IsTerminal: terminal}, env: map[string]string{"NO_COLOR": "1"}, expected: false},
+		{name: "should honour FORCE_COLOR when auto", opts: HandlerOptions{Color: ColorAuto, IsTerminal: notTerminal}, env: map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, expected: true},
+		{name: "should disable color when FORCE_COLOR is 0", opts: HandlerOptions{Color: ColorAuto, IsTerminal: terminal}, env: map[string]string{"FORCE_COLOR": "0"}, expected: false},
+		{name: "should honour CLICOLOR_FORCE when auto", opts: HandlerOptions{Color: ColorAuto, IsTerminal: notTerminal}, env: map[string]string{"CLICOLOR_FORCE": "1"}, expected: true},
+		{name: "should ignore CLICOLOR_FORCE of 0", opts: HandlerOptions{Color: ColorAuto, IsTerminal: notTerminal}, env: map[string]string{"CLICOLOR_FORCE": "0"}, expected: false},
+		{name: "should not use color on dumb terminal", opts: HandlerOptions{Color: ColorAuto, IsTerminal: terminal}, env: map[string]string{"TERM": "dumb"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"FORCE_COLOR", "NO_COLOR", "CLICOLOR_FORCE", "TERM"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			if got := useColor(nil, tt.opts); got != tt.expected {
+				t.Errorf("Expected useColor to be %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	t.Run("should not treat a buffer as terminal", func(t *testing.T) {
+		if isTerminal(&bytes.Buffer{}) {
+			t.Error("Expected buffer not to be a terminal")
+		}
+	})
+
+	t.Run("should not treat a regular file as terminal", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "log")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		if isTerminal(f) {
+			t.Error("Expected regular file not to be a terminal")
+		}
+	})
+}
+
+func TestTextHandlerAutoColor(t *testing.T) {
+	for _, key := range []string{"FORCE_COLOR", "NO_COLOR", "CLICOLOR_FORCE", "TERM"} {
+		t.Setenv(key, "")
+	}
+
+	b := &bytes.Buffer{}
+	h := NewTextHandler(b, nil)
+	if !h.opts.DisableColor {
+		t.Error("Expected colors to be disabled when writing to a buffer by default")
+	}
+
+	h = NewTextHandler(b, &HandlerOptions{Color: ColorAuto, IsTerminal: func(w io.Writer) bool { return w == b }})
+	if h.opts.DisableColor {
+		t.Error("Expected colors to be enabled when detector reports a terminal")
+	}
+}
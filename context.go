@@ -0,0 +1,54 @@
+package slogja
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// ContextExtractor returns attributes carried by ctx, such as a request or
+// tenant ID, to be added to every record handled with that context.
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+type ctxAttrsKey struct{}
+
+// ContextWithAttrs returns a copy of ctx carrying attrs in addition to the
+// attributes already added to ctx. Handlers from this package add them to
+// every record logged with the returned context.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+
+	all := append(slices.Clip(AttrsFromContext(ctx)), attrs...)
+	return context.WithValue(ctx, ctxAttrsKey{}, all)
+}
+
+// AttrsFromContext returns the attributes added to ctx by ContextWithAttrs.
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextAttrs returns the attributes added by ContextWithAttrs followed by
+// those of each extractor.
+func contextAttrs(ctx context.Context, extractors []ContextExtractor) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	attrs := AttrsFromContext(ctx)
+	if len(extractors) == 0 {
+		return attrs
+	}
+
+	attrs = slices.Clip(attrs)
+	for _, extract := range extractors {
+		attrs = append(attrs, extract(ctx)...)
+	}
+	return attrs
+}
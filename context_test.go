@@ -0,0 +1,85 @@
+package slogja
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func tenantExtractor(ctx context.Context) []slog.Attr {
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+		return []slog.Attr{slog.String("tenant", tenant)}
+	}
+	return nil
+}
+
+func TestContextWithAttrs(t *testing.T) {
+	t.Run("should accumulate attributes", func(t *testing.T) {
+		ctx := ContextWithAttrs(context.Background(), slog.String("request_id", "r1"))
+		child := ContextWithAttrs(ctx, slog.String("user_id", "u1"))
+		sibling := ContextWithAttrs(ctx, slog.String("user_id", "u2"))
+
+		if got := AttrsFromContext(child); len(got) != 2 || got[1].Value.String() != "u1" {
+			t.Errorf("Expected child to carry request_id and user_id u1, got '%v'", got)
+		}
+		if got := AttrsFromContext(sibling); len(got) != 2 || got[1].Value.String() != "u2" {
+			t.Errorf("Expected sibling to carry request_id and user_id u2, got '%v'", got)
+		}
+		if got := AttrsFromContext(ctx); len(got) != 1 {
+			t.Errorf("Expected parent to carry only request_id, got '%v'", got)
+		}
+	})
+
+	t.Run("should return nil for nil context", func(t *testing.T) {
+		var ctx context.Context
+		if got := AttrsFromContext(ctx); got != nil {
+			t.Errorf("Expected nil, got '%v'", got)
+		}
+	})
+}
+
+func TestHandlerContextAttrs(t *testing.T) {
+	ctx := ContextWithAttrs(context.Background(), slog.String("request_id", "r1"))
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+
+	t.Run("should append context attributes in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor:      true,
+			DisableEmoji:      true,
+			DisableTime:       true,
+			ContextExtractors: []ContextExtractor{tenantExtractor},
+		}))
+
+		l.WithGroup("g").InfoContext(ctx, "msg", "key", "value")
+
+		expected := `INF "msg" g.key="value" g.request_id="r1" g.tenant="acme" ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should append context attributes in json handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, &HandlerOptions{
+			DisableTime:       true,
+			ContextExtractors: []ContextExtractor{tenantExtractor},
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == "tenant" {
+					return slog.String(a.Key, "***")
+				}
+				return a
+			},
+		}))
+
+		l.WithGroup("g").InfoContext(ctx, "msg")
+
+		expected := `{"level":"INFO","msg":"msg","g":{"request_id":"r1","tenant":"***"}}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+}
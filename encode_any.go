@@ -0,0 +1,500 @@
+package slogja
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxLogValues bounds the LogValue calls nested on one path of a walk, as
+// slog does for Value.Resolve, so that a LogValuer returning a value that
+// contains itself terminates.
+const maxLogValues = 100
+
+var (
+	logValuerType = reflect.TypeFor[slog.LogValuer]()
+	slogValueType = reflect.TypeFor[slog.Value]()
+	timeType      = reflect.TypeFor[time.Time]()
+	durationType  = reflect.TypeFor[time.Duration]()
+)
+
+// PointerMode controls how pointers are written by the handlers.
+type PointerMode int
+
+const (
+	// PointerValue follows pointers and writes the value they point to. A
+	// pointer, map or slice already being written further up the same
+	// value is written as <cycle>.
+	PointerValue PointerMode = iota
+	// PointerAddress writes pointers as their address without following
+	// them, which keeps large or shared structures cheap on hot paths.
+	PointerAddress
+)
+
+// cycleMarker replaces a value that contains itself.
+const cycleMarker = "<cycle>"
+
+// anyEncoder is implemented by every output format. writeAny walks the value
+// with reflection and calls back into the encoder for scalars and punctuation,
+// so text and JSON share the same traversal.
+type anyEncoder interface {
+	writeBool(buf *buffer, b bool)
+	writeInt(buf *buffer, i int64)
+	writeUint(buf *buffer, u uint64, base int)
+	writeFloat(buf *buffer, f float64)
+	writeString(buf *buffer, s string)
+	writeStringer(buf *buffer, s string)
+	writeError(buf *buffer, msg string)
+	writeNil(buf *buffer)
+	writePointer(buf *buffer, p uintptr)
+	writeDuration(buf *buffer, d time.Duration)
+	writeTimeValue(buf *buffer, t time.Time)
+
+	writeOpen(buf *buffer, kind reflect.Kind)
+	writeClose(buf *buffer, kind reflect.Kind)
+	writeSep(buf *buffer)
+	writeFieldName(buf *buffer, name string)
+	writeMapKey(buf *buffer, key reflect.Value)
+	writeMore(buf *buffer, kind reflect.Kind, n int)
+	writeRawJSON(buf *buffer, data []byte)
+	writeDump(buf *buffer, b []byte)
+}
+
+// anyWalker holds the state of one writeAny call.
+type anyWalker struct {
+	e         anyEncoder
+	cfg       *walkConfig
+	opt       *HandlerOptions
+	logValues int // LogValue calls on the current path
+	depth     int // collections open on the current path
+
+	// visiting holds the pointers, maps and slices on the current path.
+	visiting map[visitKey]struct{}
+}
+
+// visitKey identifies a pointer, map or slice for cycle detection. The type
+// and length tell apart a struct and its first field, or a slice and a
+// prefix of it, that share an address.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// walkerPool reuses walkers, and the map of their visiting set, across
+// writeAny calls.
+var walkerPool = sync.Pool{
+	New: func() any { return new(anyWalker) },
+}
+
+func writeAny(e anyEncoder, cfg *walkConfig, buf *buffer, val reflect.Value) {
+	w := walkerPool.Get().(*anyWalker)
+	*w = anyWalker{e: e, cfg: cfg, opt: cfg.opt, visiting: w.visiting}
+	w.walk(buf, val)
+
+	clear(w.visiting)
+	*w = anyWalker{visiting: w.visiting}
+	walkerPool.Put(w)
+}
+
+// walk writes val with the encoder compiled for its type.
+func (w *anyWalker) walk(buf *buffer, val reflect.Value) {
+	if !val.IsValid() {
+		w.e.writeNil(buf)
+		return
+	}
+	w.cfg.encoder(val.Type())(w, buf, val)
+}
+
+func (w *anyWalker) walkSlogValue(buf *buffer, val reflect.Value) {
+	w.walkValue(buf, val.Interface().(slog.Value))
+}
+
+func (w *anyWalker) walkLogValuer(buf *buffer, val reflect.Value) {
+	w.walkValue(buf, slog.AnyValue(val.Interface()))
+}
+
+func (w *anyWalker) walkTime(buf *buffer, val reflect.Value) {
+	if val.CanAddr() {
+		w.e.writeTimeValue(buf, *val.Addr().Interface().(*time.Time))
+		return
+	}
+	w.e.writeTimeValue(buf, val.Interface().(time.Time))
+}
+
+func (w *anyWalker) walkDuration(buf *buffer, val reflect.Value) {
+	w.e.writeDuration(buf, time.Duration(val.Int()))
+}
+
+func (w *anyWalker) walkError(buf *buffer, val reflect.Value) {
+	err := interfaceOf(val).(error)
+	w.e.writeError(buf, truncateString(errorMessage(err), w.opt.MaxStringLength))
+}
+
+func (w *anyWalker) walkBool(buf *buffer, val reflect.Value) {
+	w.e.writeBool(buf, val.Bool())
+}
+
+func (w *anyWalker) walkInt(buf *buffer, val reflect.Value) {
+	w.e.writeInt(buf, val.Int())
+}
+
+func (w *anyWalker) walkUint(buf *buffer, val reflect.Value) {
+	w.e.writeUint(buf, val.Uint(), 10)
+}
+
+func (w *anyWalker) walkFloat(buf *buffer, val reflect.Value) {
+	w.e.writeFloat(buf, val.Float())
+}
+
+func (w *anyWalker) walkComplex(buf *buffer, val reflect.Value) {
+	w.e.writeStringer(buf, strconv.FormatComplex(val.Complex(), 'g', -1, 128))
+}
+
+func (w *anyWalker) walkString(buf *buffer, val reflect.Value) {
+	w.e.writeString(buf, truncateString(val.String(), w.opt.MaxStringLength))
+}
+
+func (w *anyWalker) walkStruct(buf *buffer, val reflect.Value, fields []structField) {
+	if !w.nest(buf) {
+		return
+	}
+	defer w.unnest()
+
+	w.e.writeOpen(buf, reflect.Struct)
+	w.walkFields(buf, val, fields, true)
+	w.e.writeClose(buf, reflect.Struct)
+}
+
+func (w *anyWalker) walkSlice(buf *buffer, val reflect.Value) {
+	e := w.e
+
+	if val.Kind() == reflect.Slice && val.Len() > 0 {
+		if !w.enter(val) {
+			e.writeStringer(buf, cycleMarker)
+			return
+		}
+		defer w.leave(val)
+	}
+	if !w.nest(buf) {
+		return
+	}
+	defer w.unnest()
+
+	e.writeOpen(buf, reflect.Slice)
+	n := w.limit(val.Len())
+	if n > 0 {
+		// All elements share a type, so its encoder is looked up once.
+		enc := w.cfg.encoder(val.Type().Elem())
+		for i := range n {
+			if i > 0 {
+				e.writeSep(buf)
+			}
+			enc(w, buf, val.Index(i))
+		}
+	}
+	w.writeMore(buf, reflect.Slice, n, val.Len())
+	e.writeClose(buf, reflect.Slice)
+}
+
+func (w *anyWalker) walkMap(buf *buffer, val reflect.Value) {
+	e := w.e
+
+	if val.Len() > 0 {
+		if !w.enter(val) {
+			e.writeStringer(buf, cycleMarker)
+			return
+		}
+		defer w.leave(val)
+	}
+	if !w.nest(buf) {
+		return
+	}
+	defer w.unnest()
+
+	e.writeOpen(buf, reflect.Map)
+	entries := mapEntries(val)
+	if !w.opt.UnsortedMapKeys {
+		sortMapEntries(entries)
+	}
+	n := w.limit(len(entries))
+	if n > 0 {
+		enc := w.cfg.encoder(val.Type().Elem())
+		for i, entry := range entries[:n] {
+			if i > 0 {
+				e.writeSep(buf)
+			}
+			e.writeMapKey(buf, entry.key)
+			enc(w, buf, entry.value)
+		}
+	}
+	w.writeMore(buf, reflect.Map, n, len(entries))
+	e.writeClose(buf, reflect.Map)
+}
+
+func (w *anyWalker) walkInterface(buf *buffer, val reflect.Value) {
+	if val.IsNil() {
+		w.e.writeNil(buf)
+		return
+	}
+	w.walk(buf, val.Elem())
+}
+
+func (w *anyWalker) walkPtr(buf *buffer, val reflect.Value) {
+	if val.IsNil() {
+		w.e.writeNil(buf)
+		return
+	}
+	if w.opt.Pointer == PointerAddress {
+		w.e.writePointer(buf, val.Pointer())
+		return
+	}
+	if !w.enter(val) {
+		w.e.writeStringer(buf, cycleMarker)
+		return
+	}
+	defer w.leave(val)
+	w.walk(buf, val.Elem())
+}
+
+// walkAddress writes a channel, function or unsafe pointer as its address.
+func (w *anyWalker) walkAddress(buf *buffer, val reflect.Value) {
+	if val.IsNil() {
+		w.e.writeNil(buf)
+		return
+	}
+	w.e.writePointer(buf, val.Pointer())
+}
+
+func (w *anyWalker) walkNil(buf *buffer, val reflect.Value) {
+	w.e.writeNil(buf)
+}
+
+// nest opens a level of nesting and reports true, or writes the depth
+// marker and reports false when MaxDepth levels are already open.
+func (w *anyWalker) nest(buf *buffer) bool {
+	if !w.canNest() {
+		w.e.writeStringer(buf, depthMarker)
+		return false
+	}
+	w.depth++
+	return true
+}
+
+// canNest reports whether fewer than MaxDepth levels are open.
+func (w *anyWalker) canNest() bool {
+	max := w.opt.MaxDepth
+	return max <= 0 || w.depth < max
+}
+
+// unnest closes the level opened by nest.
+func (w *anyWalker) unnest() {
+	w.depth--
+}
+
+// limit returns how many of n elements fit in MaxElements.
+func (w *anyWalker) limit(n int) int {
+	return limitElements(w.opt.MaxElements, n)
+}
+
+// writeMore writes the marker for the elements left out by limit, if any.
+func (w *anyWalker) writeMore(buf *buffer, kind reflect.Kind, written, n int) {
+	if written == n {
+		return
+	}
+	if written > 0 {
+		w.e.writeSep(buf)
+	}
+	w.e.writeMore(buf, kind, n-written)
+}
+
+// enter marks the pointer, map or slice val as being written and reports
+// false if it already is, meaning val contains itself.
+func (w *anyWalker) enter(val reflect.Value) bool {
+	k := visitKey{ptr: val.Pointer(), typ: val.Type()}
+	if val.Kind() == reflect.Slice {
+		k.len = val.Len()
+	}
+
+	if _, ok := w.visiting[k]; ok {
+		return false
+	}
+	if w.visiting == nil {
+		w.visiting = make(map[visitKey]struct{})
+	}
+	w.visiting[k] = struct{}{}
+	return true
+}
+
+// leave undoes enter once val has been written.
+func (w *anyWalker) leave(val reflect.Value) {
+	k := visitKey{ptr: val.Pointer(), typ: val.Type()}
+	if val.Kind() == reflect.Slice {
+		k.len = val.Len()
+	}
+	delete(w.visiting, k)
+}
+
+// walkFields writes the fields of the struct val as directed by their tags.
+// first reports whether no field has been written yet in the enclosing
+// object, and the updated value is returned for inlined structs.
+func (w *anyWalker) walkFields(buf *buffer, val reflect.Value, fields []structField, first bool) bool {
+	e := w.e
+
+	for _, f := range fields {
+		fv := val.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if f.inline {
+			if isNilRef(fv) {
+				continue
+			}
+			var ok bool
+			if first, ok = w.walkInline(buf, fv, first); ok {
+				continue
+			}
+		}
+
+		if !first {
+			e.writeSep(buf)
+		}
+		first = false
+
+		e.writeFieldName(buf, f.name)
+		if f.redact {
+			e.writeString(buf, redacted)
+		} else {
+			w.walk(buf, fv)
+		}
+	}
+	return first
+}
+
+// walkInline writes the fields of fv, a struct or a non-nil pointer to one,
+// into the enclosing object like walkFields. It writes nothing and reports
+// false when fv is to be written as a field of its own instead: a pointer
+// that is not followed or contains itself, or a struct nested deeper than
+// MaxDepth.
+func (w *anyWalker) walkInline(buf *buffer, fv reflect.Value, first bool) (bool, bool) {
+	if !w.canNest() {
+		return first, false
+	}
+	if fv.Kind() == reflect.Ptr {
+		if w.opt.Pointer == PointerAddress || !w.enter(fv) {
+			return first, false
+		}
+		defer w.leave(fv)
+		fv = fv.Elem()
+	}
+	w.depth++
+	defer w.unnest()
+	return w.walkFields(buf, fv, w.cfg.fields(fv.Type()), first), true
+}
+
+// walkValue writes a slog.Value found inside a value, resolving LogValuers
+// and writing groups as objects.
+func (w *anyWalker) walkValue(buf *buffer, v slog.Value) {
+	e := w.e
+
+	if v.Kind() == slog.KindLogValuer {
+		if w.logValues >= maxLogValues {
+			e.writeError(buf, fmt.Sprintf("LogValue called too many times on Value of type %T", v.Any()))
+			return
+		}
+		w.logValues++
+		defer func() { w.logValues-- }()
+
+		var panicked string
+		if v, panicked = resolveValue(v); panicked != "" {
+			e.writeStringer(buf, panicked)
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case slog.KindBool:
+		e.writeBool(buf, v.Bool())
+	case slog.KindInt64:
+		e.writeInt(buf, v.Int64())
+	case slog.KindUint64:
+		e.writeUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		e.writeFloat(buf, v.Float64())
+	case slog.KindString:
+		e.writeString(buf, truncateString(v.String(), w.opt.MaxStringLength))
+	case slog.KindTime:
+		e.writeTimeValue(buf, v.Time())
+	case slog.KindDuration:
+		e.writeDuration(buf, v.Duration())
+	case slog.KindGroup:
+		if !w.nest(buf) {
+			return
+		}
+		defer w.unnest()
+
+		attrs := v.Group()
+		n := w.limit(len(attrs))
+		e.writeOpen(buf, reflect.Struct)
+		for i, a := range attrs[:n] {
+			if i > 0 {
+				e.writeSep(buf)
+			}
+			e.writeFieldName(buf, a.Key)
+			w.walkValue(buf, a.Value)
+		}
+		w.writeMore(buf, reflect.Struct, n, len(attrs))
+		e.writeClose(buf, reflect.Struct)
+	case slog.KindAny:
+		w.walk(buf, reflect.ValueOf(v.Any()))
+	}
+}
+
+// isNilRef reports whether val is a nil pointer or interface.
+func isNilRef(val reflect.Value) bool {
+	return (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && val.IsNil()
+}
+
+// resolveValue is slog.Value.Resolve, except that a panicking LogValue is
+// reported as a !PANIC marker rather than as an error carrying the stack.
+func resolveValue(v slog.Value) (rv slog.Value, panicked string) {
+	orig := v
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = panicMarker("LogValue", r)
+		}
+	}()
+
+	for range maxLogValues {
+		if v.Kind() != slog.KindLogValuer {
+			return v, ""
+		}
+		v = v.LogValuer().LogValue()
+	}
+	return slog.AnyValue(fmt.Errorf("LogValue called too many times on Value of type %T", orig.Any())), ""
+}
+
+// callString returns the result of fn, the String or Error method named by
+// method, or a !PANIC marker if it panics.
+func callString(method string, fn func() string) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = panicMarker(method, r)
+		}
+	}()
+	return fn()
+}
+
+// errorMessage returns err.Error(), or a !PANIC marker if it panics.
+func errorMessage(err error) string {
+	return callString("Error", err.Error)
+}
+
+// panicMarker formats the value recovered from a panicking method so that it
+// shows up in the output instead of crashing the program.
+func panicMarker(method string, r any) string {
+	return fmt.Sprintf("!PANIC(%s: %v)", method, r)
+}
@@ -0,0 +1,402 @@
+package slogja
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type secret string
+
+func (secret) LogValue() slog.Value {
+	return slog.StringValue("***")
+}
+
+type account struct {
+	Name     string
+	Password secret
+}
+
+func (a account) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", a.Name), slog.Any("password", a.Password))
+}
+
+// selfValuer returns a new value that contains another selfValuer.
+type selfValuer struct{ n int }
+
+func (s selfValuer) LogValue() slog.Value {
+	return slog.AnyValue([]selfValuer{{n: s.n + 1}})
+}
+
+// loopValuer returns itself.
+type loopValuer struct{}
+
+func (l loopValuer) LogValue() slog.Value {
+	return slog.AnyValue(l)
+}
+
+func TestWriteAnyLogValuer(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		text  string
+		json  string
+	}{
+		{
+			name: "should resolve struct field",
+			value: struct {
+				User     string
+				Password secret
+			}{User: "alice", Password: "hunter2"},
+			text: `{User:"alice" Password:"***"}`,
+			json: `{"User":"alice","Password":"***"}`,
+		},
+		{
+			name:  "should resolve slice element",
+			value: []secret{"a", "b"},
+			text:  `["***" "***"]`,
+			json:  `["***","***"]`,
+		},
+		{
+			name:  "should resolve map value",
+			value: map[string]secret{"token": "t"},
+			text:  `["token":"***"]`,
+			json:  `{"token":"***"}`,
+		},
+		{
+			name:  "should resolve nested group",
+			value: []account{{Name: "alice", Password: "p"}},
+			text:  `[{name:"alice" password:"***"}]`,
+			json:  `[{"name":"alice","password":"***"}]`,
+		},
+		{
+			name:  "should resolve slog.Value",
+			value: []slog.Value{slog.IntValue(1), slog.AnyValue(secret("s"))},
+			text:  `[1 "***"]`,
+			json:  `[1,"***"]`,
+		},
+		{
+			name:  "should stop LogValue returning itself",
+			value: []loopValuer{{}},
+			text:  `["LogValue called too many times on Value of type slogja.loopValuer"]`,
+			json:  `["LogValue called too many times on Value of type slogja.loopValuer"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer()
+			defer buf.Free()
+
+			newEncodeText(HandlerOptions{DisableColor: true}).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.text {
+				t.Errorf("Expected text to contain '%s', got '%s'", tt.text, string(*buf))
+			}
+
+			*buf = (*buf)[:0]
+			newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.json {
+				t.Errorf("Expected json to contain '%s', got '%s'", tt.json, string(*buf))
+			}
+		})
+	}
+
+	t.Run("should stop LogValue returning a value containing itself", func(t *testing.T) {
+		buf := newBuffer()
+		defer buf.Free()
+
+		newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(selfValuer{}))
+		expected := `"LogValue called too many times on Value of type slogja.selfValuer"`
+		if !strings.Contains(string(*buf), expected) {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+}
+
+type panicStringer struct{ N int }
+
+func (panicStringer) String() string { panic("string boom") }
+
+type panicError struct{}
+
+func (panicError) Error() string { panic("error boom") }
+
+type panicValuer struct{}
+
+func (panicValuer) LogValue() slog.Value { panic("value boom") }
+
+type panicUnwrap struct{}
+
+func (panicUnwrap) Error() string { return "outer" }
+func (panicUnwrap) Unwrap() error { panic("unwrap boom") }
+
+func TestWriteAnyNeverPanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		text  string
+		json  string
+	}{
+		{
+			name: "should skip unexported nested struct",
+			value: struct {
+				Name  string
+				inner struct{ T Data }
+			}{Name: "a"},
+			text: `{Name:"a"}`,
+			json: `{"Name":"a"}`,
+		},
+		{
+			name:  "should recover from panicking String",
+			value: []any{panicStringer{}},
+			text:  `["!PANIC(String: string boom)"]`,
+			json:  `["!PANIC(String: string boom)"]`,
+		},
+		{
+			name:  "should recover from panicking Error",
+			value: []any{panicError{}},
+			text:  `["!PANIC(Error: error boom)"]`,
+			json:  `["!PANIC(Error: error boom)"]`,
+		},
+		{
+			name:  "should recover from panicking LogValue",
+			value: []any{panicValuer{}},
+			text:  `["!PANIC(LogValue: value boom)"]`,
+			json:  `["!PANIC(LogValue: value boom)"]`,
+		},
+		{
+			name:  "should recover from panicking String in map key",
+			value: map[panicStringer]int{{}: 1},
+			text:  `["!PANIC(String: string boom)":1]`,
+			json:  `{"!PANIC(String: string boom)":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer()
+			defer buf.Free()
+
+			newEncodeText(HandlerOptions{DisableColor: true}).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.text {
+				t.Errorf("Expected text to contain '%s', got '%s'", tt.text, string(*buf))
+			}
+
+			*buf = (*buf)[:0]
+			newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.json {
+				t.Errorf("Expected json to contain '%s', got '%s'", tt.json, string(*buf))
+			}
+			if !json.Valid(*buf) {
+				t.Errorf("Expected valid json, got '%s'", string(*buf))
+			}
+		})
+	}
+
+	t.Run("should recover from panicking Unwrap in error chain", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			DisableTime:  true,
+			ErrorChain:   true,
+		}))
+
+		l.Error("failed", "err", panicUnwrap{})
+
+		expected := "ERR \"failed\" err=\"outer\" \n  err:\n    !PANIC(Unwrap: unwrap boom)\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestWriteAnyPointers(t *testing.T) {
+	ring := &node{Name: "a", Next: &node{Name: "b"}}
+	ring.Next.Next = ring
+
+	shared := &node{Name: "shared"}
+
+	loop := []any{1, nil}
+	loop[1] = loop
+
+	m := map[string]any{}
+	m["self"] = m
+
+	tests := []struct {
+		name  string
+		value any
+		text  string
+		json  string
+	}{
+		{
+			name:  "should follow pointer",
+			value: node{Name: "a", Next: &node{Name: "b"}},
+			text:  `{Name:"a" Next:{Name:"b" Next:nil}}`,
+			json:  `{"Name":"a","Next":{"Name":"b","Next":null}}`,
+		},
+		{
+			name:  "should follow pointer to interface",
+			value: func() *any { var v any = 1; return &v }(),
+			text:  `1`,
+			json:  `1`,
+		},
+		{
+			name:  "should mark pointer cycle",
+			value: ring,
+			text:  `{Name:"a" Next:{Name:"b" Next:<cycle>}}`,
+			json:  `{"Name":"a","Next":{"Name":"b","Next":"<cycle>"}}`,
+		},
+		{
+			name:  "should write shared pointer twice",
+			value: []*node{shared, shared},
+			text:  `[{Name:"shared" Next:nil} {Name:"shared" Next:nil}]`,
+			json:  `[{"Name":"shared","Next":null},{"Name":"shared","Next":null}]`,
+		},
+		{
+			name:  "should mark slice cycle",
+			value: loop,
+			text:  `[1 <cycle>]`,
+			json:  `[1,"<cycle>"]`,
+		},
+		{
+			name:  "should mark map cycle",
+			value: map[string]any{"m": map[string]any{"self": m}},
+			text:  `["m":["self":["self":<cycle>]]]`,
+			json:  `{"m":{"self":{"self":"<cycle>"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer()
+			defer buf.Free()
+
+			newEncodeText(HandlerOptions{DisableColor: true}).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.text {
+				t.Errorf("Expected text to contain '%s', got '%s'", tt.text, string(*buf))
+			}
+
+			*buf = (*buf)[:0]
+			newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.json {
+				t.Errorf("Expected json to contain '%s', got '%s'", tt.json, string(*buf))
+			}
+		})
+	}
+}
+
+// fuzzStruct mixes exported, unexported, pointer and map fields so that
+// fuzzValue can build arbitrarily shaped values from it.
+type fuzzStruct struct {
+	A any
+	B *fuzzStruct
+	c any
+	D map[string]any
+	Data
+}
+
+// fuzzValue builds a value of a random shape from data.
+func fuzzValue(data *[]byte, depth int) any {
+	next := func() byte {
+		if len(*data) == 0 {
+			return 0
+		}
+		b := (*data)[0]
+		*data = (*data)[1:]
+		return b
+	}
+
+	kind := next()
+	if depth > 4 {
+		kind %= 4
+	}
+
+	switch kind % 20 {
+	case 0:
+		return nil
+	case 1:
+		return int8(next())
+	case 2:
+		n := int(next()) % (len(*data) + 1)
+		s := string((*data)[:n])
+		*data = (*data)[n:]
+		return s
+	case 3:
+		return []float64{math.NaN(), math.Inf(int(next()%2)*2 - 1)}[next()%2]
+	case 4:
+		return []any{fuzzValue(data, depth+1), fuzzValue(data, depth+1)}
+	case 5:
+		return [2]any{fuzzValue(data, depth+1), fuzzValue(data, depth+1)}
+	case 6:
+		return map[any]any{int(next()): fuzzValue(data, depth+1), string(next()): fuzzValue(data, depth+1)}
+	case 7:
+		s := fuzzStruct{A: fuzzValue(data, depth+1), c: fuzzValue(data, depth+1)}
+		if next()%2 == 0 {
+			s.B = &fuzzStruct{D: map[string]any{"k": fuzzValue(data, depth+1)}}
+		}
+		return s
+	case 8:
+		return panicStringer{}
+	case 9:
+		return panicError{}
+	case 10:
+		return panicValuer{}
+	case 11:
+		return map[panicStringer]any{{N: int(next())}: fuzzValue(data, depth+1)}
+	case 12:
+		return (*fuzzStruct)(nil)
+	case 13:
+		return errors.Join(panicError{}, panicUnwrap{}, nil)
+	case 14:
+		return slog.GroupValue(slog.Any("g", fuzzValue(data, depth+1)))
+	case 15:
+		return complex(float64(next()), -1)
+	case 16:
+		return make(chan int)
+	case 17:
+		return loopValuer{}
+	case 18:
+		return struct {
+			X    any
+			priv struct{ S panicStringer }
+		}{X: fuzzValue(data, depth+1)}
+	default:
+		var iface any = fuzzValue(data, depth+1)
+		return &iface
+	}
+}
+
+func FuzzWriteAny(f *testing.F) {
+	for _, seed := range []string{"", "\x04\x08\x09", "\x07\x06\x02\x03abc", "\x0b\x12\x0d\x0e\x13\x11"} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		v := fuzzValue(&data, 0)
+
+		buf := newBuffer()
+		defer buf.Free()
+		newEncodeText(HandlerOptions{}).writeAny(buf, reflect.ValueOf(v))
+
+		*buf = (*buf)[:0]
+		newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(v))
+		if !json.Valid(*buf) {
+			t.Errorf("Expected valid json for %#v, got '%s'", v, string(*buf))
+		}
+
+		b := bytes.NewBuffer([]byte{})
+		slog.New(NewTextHandler(b, &HandlerOptions{ErrorChain: true, ErrorStack: true})).Info("msg", "v", v)
+		slog.New(NewJSONHandler(b, nil)).Info("msg", "v", v)
+	})
+}
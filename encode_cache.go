@@ -0,0 +1,236 @@
+package slogja
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// encoderFunc writes a value of the type it was compiled for. Which checks
+// apply to a type, such as its methods and struct tags, is decided once by
+// compile, so writing the same types again costs no reflection on them.
+type encoderFunc func(w *anyWalker, buf *buffer, val reflect.Value)
+
+// walkConfig is what writeAny needs from a handler, prepared once when the
+// handler is created. Encoders depend on the options, so each handler has
+// its own cache, shared by the handlers derived from it.
+type walkConfig struct {
+	opt       *HandlerOptions
+	renderers map[reflect.Type]renderFunc
+
+	encoders *sync.Map // reflect.Type to encoderFunc
+	structs  *sync.Map // reflect.Type to []structField
+}
+
+func newWalkConfig(opt *HandlerOptions) walkConfig {
+	c := walkConfig{
+		opt:      opt,
+		encoders: &sync.Map{},
+		structs:  &sync.Map{},
+	}
+	if len(opt.Renderers) > 0 {
+		c.renderers = make(map[reflect.Type]renderFunc, len(opt.Renderers))
+		for _, r := range opt.Renderers {
+			render := r.Render
+			c.renderers[r.Type] = func(val reflect.Value) slog.Value {
+				return render(val.Interface())
+			}
+		}
+	}
+	return c
+}
+
+// encoder returns the encoder of t, compiling it on first use.
+func (c *walkConfig) encoder(t reflect.Type) encoderFunc {
+	if fn, ok := c.encoders.Load(t); ok {
+		return fn.(encoderFunc)
+	}
+	fn, _ := c.encoders.LoadOrStore(t, c.compile(t))
+	return fn.(encoderFunc)
+}
+
+// compile returns the encoder of t. The checks are made in order of
+// precedence: renderers, pointers to be followed, LogValue, times and
+// durations, errors, raw JSON, String and friends, bytes and finally the
+// kind of t. Encoders of nested
+// types are looked up when they are first needed, so recursive types
+// compile without looping.
+func (c *walkConfig) compile(t reflect.Type) encoderFunc {
+	kind := c.kindEncoder(t)
+	if fn := c.typeEncoder(t, kind); fn != nil {
+		return fn
+	}
+	return kind
+}
+
+// typeEncoder returns the encoder of t chosen by compile before falling
+// back to kind, or nil if t is written by its kind alone.
+func (c *walkConfig) typeEncoder(t reflect.Type, kind encoderFunc) encoderFunc {
+	if fn, ok := c.renderers[t]; ok {
+		return withInterface(kind, func(w *anyWalker, buf *buffer, val reflect.Value) {
+			w.render(buf, fn, val)
+		})
+	}
+	if t.Kind() == reflect.Ptr && c.followPointer(t.Elem()) {
+		return nil
+	}
+
+	switch {
+	case t == slogValueType:
+		// A LogValuer or slog.Value nested anywhere in the value is
+		// resolved like a top-level attribute, so a type hiding its
+		// fields stays hidden.
+		return withInterface(kind, (*anyWalker).walkSlogValue)
+	case t.Implements(logValuerType):
+		return withInterface(kind, (*anyWalker).walkLogValuer)
+	case t == timeType:
+		// Times and durations are written like the slog kinds of the
+		// same name rather than as a struct or an integer.
+		return withInterface(kind, (*anyWalker).walkTime)
+	case t == durationType:
+		return (*anyWalker).walkDuration
+	case t.Implements(errorType):
+		// Errors come before methods so that the message is written
+		// instead of the fields or address of the error value.
+		return withInterface(kind, (*anyWalker).walkError)
+	case t == rawMessageType:
+		// json.RawMessage may have a String method.
+		return (*anyWalker).walkBytes
+	}
+
+	if t.Kind() != reflect.Ptr || c.opt.Pointer != PointerAddress {
+		if fn := methodEncoder(t); fn != nil {
+			return withInterface(kind, fn)
+		}
+	}
+
+	if isBytes(t) {
+		return (*anyWalker).walkBytes
+	}
+	return nil
+}
+
+// inlinable reports whether a field of type t tagged inline has its fields
+// written into the enclosing struct. Only a struct, or a pointer to one,
+// written by its fields alone is inlined; any other field, such as a time
+// or a LogValuer hiding its fields, is written as a field of its own. So
+// is a field tagged redact as well.
+func (c *walkConfig) inlinable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		if c.typeEncoder(t, nil) != nil {
+			return false
+		}
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && c.typeEncoder(t, nil) == nil
+}
+
+// followPointer reports whether a pointer to t is written as the value it
+// points to before its methods are looked at, so that a time, duration,
+// raw JSON, byte slice or rendered type is written the same way through a
+// pointer as without it.
+func (c *walkConfig) followPointer(t reflect.Type) bool {
+	if _, ok := c.renderers[t]; ok {
+		return true
+	}
+	return t == timeType || t == durationType || t == rawMessageType || isBytes(t)
+}
+
+// withInterface returns an encoder that writes with fn the values it can
+// call methods on and falls back to kind for nil pointers and interfaces.
+func withInterface(kind, fn encoderFunc) encoderFunc {
+	return func(w *anyWalker, buf *buffer, val reflect.Value) {
+		if !val.CanInterface() || isNilRef(val) {
+			kind(w, buf, val)
+			return
+		}
+		fn(w, buf, val)
+	}
+}
+
+// kindEncoder returns the encoder of t by its kind alone.
+func (c *walkConfig) kindEncoder(t reflect.Type) encoderFunc {
+	switch t.Kind() {
+	case reflect.Bool:
+		return (*anyWalker).walkBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return (*anyWalker).walkInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return (*anyWalker).walkUint
+	case reflect.Float32, reflect.Float64:
+		return (*anyWalker).walkFloat
+	case reflect.Complex64, reflect.Complex128:
+		return (*anyWalker).walkComplex
+	case reflect.String:
+		return (*anyWalker).walkString
+	case reflect.Struct:
+		fields := c.fields(t)
+		return func(w *anyWalker, buf *buffer, val reflect.Value) {
+			w.walkStruct(buf, val, fields)
+		}
+	case reflect.Slice, reflect.Array:
+		return (*anyWalker).walkSlice
+	case reflect.Map:
+		return (*anyWalker).walkMap
+	case reflect.Interface:
+		return (*anyWalker).walkInterface
+	case reflect.Ptr:
+		return (*anyWalker).walkPtr
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return (*anyWalker).walkAddress
+	}
+	return (*anyWalker).walkNil
+}
+
+// structField is an exported struct field with its parsed tag.
+type structField struct {
+	index     int
+	name      string
+	omitEmpty bool
+	redact    bool
+	inline    bool
+}
+
+// fields returns the fields of the struct type t that are written, in
+// order, parsing their tags on first use.
+func (c *walkConfig) fields(t reflect.Type) []structField {
+	if fs, ok := c.structs.Load(t); ok {
+		return fs.([]structField)
+	}
+
+	var fs []structField
+	for i := range t.NumField() {
+		// Unexported fields are skipped like encoding/json does: their
+		// values cannot be passed to String, Error or LogValue, so writing
+		// them would bypass whatever the type chose to hide.
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := parseFieldTag(f, c.opt.JSONTags)
+		if tag.skip {
+			continue
+		}
+		fs = append(fs, structField{
+			index:     i,
+			name:      tag.name,
+			omitEmpty: tag.omitEmpty,
+			redact:    tag.redact,
+			inline:    tag.inline && !tag.redact && c.inlinable(f.Type),
+		})
+	}
+
+	v, _ := c.structs.LoadOrStore(t, fs)
+	return v.([]structField)
+}
+
+// interfaceOf returns val as an interface value for calling its methods. An
+// addressable val is returned as a pointer, which has the same methods and
+// avoids the copy that Interface makes.
+func interfaceOf(val reflect.Value) any {
+	if val.CanAddr() && val.Kind() != reflect.Ptr && val.Kind() != reflect.Interface {
+		return val.Addr().Interface()
+	}
+	return val.Interface()
+}
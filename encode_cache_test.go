@@ -0,0 +1,108 @@
+package slogja
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cacheItem struct {
+	SKU   string
+	Price float64
+}
+
+type cacheOrder struct {
+	ID      int
+	Items   []cacheItem
+	Created time.Time
+	Note    string `slogja:",omitempty"`
+	Secret  string `slogja:",redact"`
+}
+
+// cacheNode refers to itself through a pointer and a slice.
+type cacheNode struct {
+	Name     string
+	Next     *cacheNode
+	Children []cacheNode
+}
+
+func TestEncoderCache(t *testing.T) {
+	t.Run("should compile each type once", func(t *testing.T) {
+		e := newEncodeText(HandlerOptions{DisableColor: true})
+		buf := newBuffer()
+		defer buf.Free()
+
+		e.writeAny(buf, reflect.ValueOf(cacheOrder{Items: []cacheItem{{}}}))
+
+		for _, typ := range []reflect.Type{
+			reflect.TypeFor[cacheOrder](),
+			reflect.TypeFor[[]cacheItem](),
+			reflect.TypeFor[cacheItem](),
+			reflect.TypeFor[time.Time](),
+		} {
+			if _, ok := e.walk.encoders.Load(typ); !ok {
+				t.Errorf("expected encoder of %v to be cached", typ)
+			}
+		}
+	})
+
+	t.Run("should write recursive types", func(t *testing.T) {
+		v := &cacheNode{
+			Name:     "a",
+			Next:     &cacheNode{Name: "b"},
+			Children: []cacheNode{{Name: "c"}},
+		}
+
+		buf := newBuffer()
+		defer buf.Free()
+		newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(v))
+
+		expected := `{"Name":"a","Next":{"Name":"b","Next":null,"Children":[]},"Children":[{"Name":"c","Next":null,"Children":[]}]}`
+		if string(*buf) != expected {
+			t.Errorf("expected %s but got %s", expected, *buf)
+		}
+	})
+
+	t.Run("should keep options per handler", func(t *testing.T) {
+		v := struct {
+			Name string `json:"name"`
+		}{Name: "x"}
+
+		buf := newBuffer()
+		defer buf.Free()
+		newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(v))
+		newEncodeJSON(HandlerOptions{JSONTags: true}).writeAny(buf, reflect.ValueOf(v))
+
+		expected := `{"Name":"x"}{"name":"x"}`
+		if string(*buf) != expected {
+			t.Errorf("expected %s but got %s", expected, *buf)
+		}
+	})
+
+	t.Run("should not allocate for cached struct types", func(t *testing.T) {
+		v := &cacheOrder{
+			ID:      1,
+			Items:   []cacheItem{{SKU: "a", Price: 1.5}, {SKU: "b", Price: 2}},
+			Created: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+			Secret:  "hunter2",
+		}
+		val := reflect.ValueOf(v)
+
+		for _, e := range []interface{ writeAny(*buffer, reflect.Value) }{
+			newEncodeText(HandlerOptions{DisableColor: true}),
+			newEncodeJSON(HandlerOptions{}),
+		} {
+			buf := newBuffer()
+			e.writeAny(buf, val)
+
+			allocs := testing.AllocsPerRun(100, func() {
+				*buf = (*buf)[:0]
+				e.writeAny(buf, val)
+			})
+			buf.Free()
+			if allocs != 0 {
+				t.Errorf("expected no allocations for %T but got %v", e, allocs)
+			}
+		}
+	})
+}
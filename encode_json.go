@@ -0,0 +1,407 @@
+package slogja
+
+import (
+	"log/slog"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+type encodeJSON struct {
+	opt    HandlerOptions
+	levels levelTable
+	walk   walkConfig
+}
+
+func newEncodeJSON(opt HandlerOptions) *encodeJSON {
+	e := &encodeJSON{
+		opt: opt,
+	}
+	// Level names from slog are kept unless a custom table is configured.
+	if len(opt.Levels) > 0 {
+		e.levels = newLevelTable(opt.Levels)
+	}
+	e.walk = newWalkConfig(&e.opt)
+	return e
+}
+
+func (e *encodeJSON) writeTime(buf *buffer, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	e.writeTimeAttr(buf, slog.Time(slog.TimeKey, t))
+}
+
+// writeTimeAttr writes the time attribute, formatted with TimeFormat when
+// it still holds a time after ReplaceAttr.
+func (e *encodeJSON) writeTimeAttr(buf *buffer, a slog.Attr) {
+	if e.opt.DisableTime || a.Equal(slog.Attr{}) {
+		return
+	}
+
+	val := a.Value.Resolve()
+	if val.Kind() != slog.KindTime || e.opt.TimeFormat == "" {
+		e.writeAttr(buf, a)
+		return
+	}
+
+	// A custom layout may produce characters that need escaping.
+	e.writeKey(buf, a.Key)
+	e.writeString(buf, val.Time().Format(e.opt.TimeFormat))
+	buf.WriteByte(',')
+}
+
+func (e *encodeJSON) writeLevel(buf *buffer, level slog.Level) {
+	if e.opt.DisableLevel {
+		return
+	}
+
+	e.writeKey(buf, slog.LevelKey)
+	e.writeLevelName(buf, level)
+	buf.WriteByte(',')
+}
+
+func (e *encodeJSON) writeLevelName(buf *buffer, level slog.Level) {
+	if e.levels == nil {
+		e.writeString(buf, level.String())
+		return
+	}
+
+	style, offset := e.levels.lookup(level)
+	if offset == 0 {
+		e.writeString(buf, style.Label)
+		return
+	}
+	e.writeString(buf, string(appendLevelLabel(nil, style, offset)))
+}
+
+// writeSource writes the source attribute as an object with function, file
+// and line members. A value replaced by ReplaceAttr is written as is.
+func (e *encodeJSON) writeSource(buf *buffer, a slog.Attr) {
+	if src, ok := a.Value.Any().(*slog.Source); ok {
+		a = sourceGroup(a.Key, src)
+	}
+	e.writeAttr(buf, a)
+}
+
+// writeLevelAttr writes the level attribute returned by ReplaceAttr. A
+// slog.Level value is written by name, anything else as a regular value.
+func (e *encodeJSON) writeLevelAttr(buf *buffer, a slog.Attr) {
+	if e.opt.DisableLevel || a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if level, ok := a.Value.Any().(slog.Level); ok {
+		e.writeKey(buf, a.Key)
+		e.writeLevelName(buf, level)
+		buf.WriteByte(',')
+		return
+	}
+	e.writeAttr(buf, a)
+}
+
+func (e *encodeJSON) writeMessage(buf *buffer, str string) {
+	e.writeMessageKey(buf, slog.MessageKey, str)
+}
+
+// writeMessageAttr writes the message attribute returned by ReplaceAttr.
+func (e *encodeJSON) writeMessageAttr(buf *buffer, a slog.Attr) {
+	if val := a.Value.Resolve(); val.Kind() == slog.KindString {
+		e.writeMessageKey(buf, a.Key, val.String())
+		return
+	}
+	e.writeAttr(buf, a)
+}
+
+// writeMessageKey writes the message under key, cut to MaxStringLength and
+// to the room left by MaxRecordBytes.
+func (e *encodeJSON) writeMessageKey(buf *buffer, key, str string) {
+	writeMessageLimited(e.opt, buf, str, func(buf *buffer, s string) {
+		e.writeKey(buf, key)
+		e.writeString(buf, s)
+		buf.WriteByte(',')
+	})
+}
+
+func (e *encodeJSON) writeAttr(buf *buffer, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	val := a.Value.Resolve()
+	if val.Kind() == slog.KindGroup {
+		attrs := val.Group()
+		if len(attrs) == 0 {
+			return
+		}
+
+		// A group with an empty key is inlined into its parent.
+		if a.Key != "" {
+			e.writeOpenGroup(buf, a.Key)
+		}
+		n := limitElements(e.opt.MaxElements, len(attrs))
+		for _, subAttr := range attrs[:n] {
+			e.writeAttr(buf, subAttr)
+		}
+		if n < len(attrs) {
+			e.writeMore(buf, reflect.Struct, len(attrs)-n)
+			buf.WriteByte(',')
+		}
+		if a.Key != "" {
+			e.writeCloseGroup(buf)
+		}
+	} else {
+		e.writeKey(buf, a.Key)
+		e.writeValue(buf, val)
+	}
+}
+
+func (e *encodeJSON) writeOpenGroup(buf *buffer, name string) {
+	e.writeKey(buf, name)
+	buf.WriteByte('{')
+}
+
+func (e *encodeJSON) writeCloseGroup(buf *buffer) {
+	e.writeCloseObject(buf)
+	buf.WriteByte(',')
+}
+
+// writeCloseObject replaces the trailing comma left by the last member, if
+// any, with a closing brace.
+func (e *encodeJSON) writeCloseObject(buf *buffer) {
+	if n := len(*buf); n > 0 && (*buf)[n-1] == ',' {
+		*buf = (*buf)[:n-1]
+	}
+	buf.WriteByte('}')
+}
+
+func (e *encodeJSON) writeKey(buf *buffer, key string) {
+	e.writeString(buf, key)
+	buf.WriteByte(':')
+}
+
+func (e *encodeJSON) writeValue(buf *buffer, val slog.Value) {
+	switch val.Kind() {
+	case slog.KindBool:
+		e.writeBool(buf, val.Bool())
+	case slog.KindInt64:
+		e.writeInt(buf, val.Int64())
+	case slog.KindUint64:
+		e.writeUint(buf, val.Uint64(), 10)
+	case slog.KindFloat64:
+		e.writeFloat(buf, val.Float64())
+	case slog.KindString:
+		e.writeString(buf, truncateString(val.String(), e.opt.MaxStringLength))
+	case slog.KindTime:
+		e.writeTimeValue(buf, val.Time())
+	case slog.KindDuration:
+		e.writeDuration(buf, val.Duration())
+	case slog.KindAny:
+		e.writeAny(buf, reflect.ValueOf(val.Any()))
+	}
+	buf.WriteByte(',')
+}
+
+func (e *encodeJSON) writeAny(buf *buffer, val reflect.Value) {
+	writeAny(e, &e.walk, buf, val)
+}
+
+func (e *encodeJSON) writeStringer(buf *buffer, s string) {
+	e.writeString(buf, s)
+}
+
+func (e *encodeJSON) writeError(buf *buffer, msg string) {
+	e.writeString(buf, msg)
+}
+
+func (e *encodeJSON) writeNil(buf *buffer) {
+	buf.WriteString("null")
+}
+
+func (e *encodeJSON) writePointer(buf *buffer, p uintptr) {
+	buf.WriteString(`"0x`)
+	e.writeUint(buf, uint64(p), 16)
+	buf.WriteByte('"')
+}
+
+func (e *encodeJSON) writeOpen(buf *buffer, kind reflect.Kind) {
+	if kind == reflect.Slice {
+		buf.WriteByte('[')
+	} else {
+		buf.WriteByte('{')
+	}
+}
+
+func (e *encodeJSON) writeClose(buf *buffer, kind reflect.Kind) {
+	if kind == reflect.Slice {
+		buf.WriteByte(']')
+	} else {
+		buf.WriteByte('}')
+	}
+}
+
+func (e *encodeJSON) writeSep(buf *buffer) {
+	buf.WriteByte(',')
+}
+
+func (e *encodeJSON) writeFieldName(buf *buffer, name string) {
+	e.writeKey(buf, name)
+}
+
+// writeMapKey writes a map key as a JSON object key. Keys held in an
+// interface are unwrapped. Keys that are not strings are encoded first and
+// then quoted, unless they encode to a string, such as the text of a
+// Stringer or a !PANIC marker, which would otherwise be quoted twice.
+func (e *encodeJSON) writeMapKey(buf *buffer, key reflect.Value) {
+	for key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+	if key.Kind() == reflect.String {
+		e.writeKey(buf, key.String())
+		return
+	}
+
+	tmp := newBuffer()
+	defer tmp.Free()
+	e.writeAny(tmp, key)
+	if len(*tmp) > 0 && (*tmp)[0] == '"' {
+		// Already a JSON string, e.g. from a Stringer.
+		buf.Write(*tmp)
+		buf.WriteByte(':')
+		return
+	}
+	e.writeKey(buf, string(*tmp))
+}
+
+// writeMore writes the marker for n elements left out of a collection. In an
+// object it takes the place of a member, with "…" as the key.
+func (e *encodeJSON) writeMore(buf *buffer, kind reflect.Kind, n int) {
+	if kind != reflect.Slice {
+		e.writeKey(buf, "…")
+	}
+	e.writeString(buf, string(appendMore(nil, n)))
+}
+
+// writeRawJSON writes embedded JSON as is. data must be valid and compact.
+func (e *encodeJSON) writeRawJSON(buf *buffer, data []byte) {
+	buf.Write(data)
+}
+
+// writeDump writes the hex dump of b as a string.
+func (e *encodeJSON) writeDump(buf *buffer, b []byte) {
+	e.writeString(buf, hexDump(e.opt, b))
+}
+
+func (e *encodeJSON) writeNewline(buf *buffer) {
+	buf.WriteByte('\n')
+}
+
+// writeString writes s as a quoted JSON string. Invalid UTF-8 is replaced
+// with U+FFFD and the JavaScript line terminators U+2028 and U+2029 are
+// escaped.
+func (e *encodeJSON) writeString(buf *buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+func (e *encodeJSON) writeBool(buf *buffer, b bool) {
+	*buf = strconv.AppendBool(*buf, b)
+}
+
+func (e *encodeJSON) writeInt(buf *buffer, i int64) {
+	*buf = strconv.AppendInt(*buf, i, 10)
+}
+
+func (e *encodeJSON) writeUint(buf *buffer, u uint64, base int) {
+	*buf = strconv.AppendUint(*buf, u, base)
+}
+
+// writeFloat writes f as a JSON number. NaN and infinities have no JSON
+// representation, so they are written as strings.
+func (e *encodeJSON) writeFloat(buf *buffer, f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		buf.WriteByte('"')
+		*buf = strconv.AppendFloat(*buf, f, 'g', -1, 64)
+		buf.WriteByte('"')
+		return
+	}
+	*buf = strconv.AppendFloat(*buf, f, 'g', -1, 64)
+}
+
+func (e *encodeJSON) writeDuration(buf *buffer, d time.Duration) {
+	if !durationIsString(e.opt.DurationFormat) {
+		*buf = appendDuration(*buf, d, e.opt.DurationFormat, e.opt.DurationUnit)
+		return
+	}
+	buf.WriteByte('"')
+	*buf = appendDuration(*buf, d, e.opt.DurationFormat, e.opt.DurationUnit)
+	buf.WriteByte('"')
+}
+
+// writeTimeValue writes a time-valued attribute in the TimeAttrFormat.
+func (e *encodeJSON) writeTimeValue(buf *buffer, t time.Time) {
+	f := e.opt.TimeAttrFormat
+	switch {
+	case !timeAttrIsString(f):
+		*buf = appendTimeAttr(*buf, t, f, e.opt.TimeFormat)
+	case f == TimeAttrHeader && e.opt.TimeFormat != "":
+		// A custom layout may produce characters that need escaping.
+		e.writeString(buf, t.Format(e.opt.TimeFormat))
+	default:
+		buf.WriteByte('"')
+		*buf = appendTimeAttr(*buf, t, f, e.opt.TimeFormat)
+		buf.WriteByte('"')
+	}
+}
@@ -0,0 +1,141 @@
+package slogja
+
+import (
+	"log/slog"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeJSONString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "should write plain string", input: "hello", expected: `"hello"`},
+		{name: "should escape quote and backslash", input: `a"b\c`, expected: `"a\"b\\c"`},
+		{name: "should escape newline, tab and carriage return", input: "a\nb\tc\r", expected: `"a\nb\tc\r"`},
+		{name: "should escape control characters", input: "\x00\x1b[31m", expected: `"\u0000\u001b[31m"`},
+		{name: "should replace invalid utf-8", input: "a\xffb", expected: `"a\ufffdb"`},
+		{name: "should escape line separators", input: "a\u2028b\u2029", expected: `"a\u2028b\u2029"`},
+		{name: "should keep unicode", input: "สวัสดี 🌱", expected: `"สวัสดี 🌱"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := newEncodeJSON(HandlerOptions{})
+			buf := newBuffer()
+			defer buf.Free()
+
+			encoder.writeString(buf, tt.input)
+			if string(*buf) != tt.expected {
+				t.Errorf("Expected buffer to contain '%s', got '%s'", tt.expected, string(*buf))
+			}
+		})
+	}
+}
+
+func TestEncodeJSONValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    slog.Value
+		expected string
+	}{
+		{name: "should write bool", value: slog.BoolValue(true), expected: "true,"},
+		{name: "should write int64", value: slog.Int64Value(-42), expected: "-42,"},
+		{name: "should write uint64", value: slog.Uint64Value(42), expected: "42,"},
+		{name: "should write float64", value: slog.Float64Value(3.14), expected: "3.14,"},
+		{name: "should write NaN as string", value: slog.Float64Value(math.NaN()), expected: `"NaN",`},
+		{name: "should write string", value: slog.StringValue("value"), expected: `"value",`},
+		{name: "should write duration", value: slog.DurationValue(5 * time.Second), expected: "5000000000,"},
+		{name: "should write time", value: slog.TimeValue(time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)), expected: `"2023-10-01T12:00:00.000Z",`},
+		{name: "should write nil", value: slog.AnyValue(nil), expected: "null,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := newEncodeJSON(HandlerOptions{})
+			buf := newBuffer()
+			defer buf.Free()
+
+			encoder.writeValue(buf, tt.value)
+			if string(*buf) != tt.expected {
+				t.Errorf("Expected buffer to contain '%s', got '%s'", tt.expected, string(*buf))
+			}
+		})
+	}
+}
+
+func TestEncodeJSONAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "should write slice", value: []int{1, 2, 3}, expected: "[1,2,3]"},
+		{name: "should write empty slice", value: []string{}, expected: "[]"},
+		{name: "should write struct", value: struct {
+			Name string
+			Age  int
+		}{Name: "Alice", Age: 30}, expected: `{"Name":"Alice","Age":30}`},
+		{name: "should write stringer as string", value: Data{Value: "data"}, expected: `"data"`},
+		{name: "should write map with string key", value: map[string]int{"a": 1}, expected: `{"a":1}`},
+		{name: "should quote map with int key", value: map[int]string{1: "a"}, expected: `{"1":"a"}`},
+		{name: "should unwrap map with interface key", value: map[any]int{"a": 1}, expected: `{"a":1}`},
+		{name: "should not quote stringer map key twice", value: map[Data]int{{Value: "k"}: 1}, expected: `{"k":1}`},
+		{name: "should write nil pointer", value: (*Data)(nil), expected: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := newEncodeJSON(HandlerOptions{})
+			buf := newBuffer()
+			defer buf.Free()
+
+			encoder.writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.expected {
+				t.Errorf("Expected buffer to contain '%s', got '%s'", tt.expected, string(*buf))
+			}
+		})
+	}
+}
+
+func TestEncodeJSONAttr(t *testing.T) {
+	t.Run("should write group as nested object", func(t *testing.T) {
+		encoder := newEncodeJSON(HandlerOptions{})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeAttr(buf, slog.Group("g", slog.String("key", "value"), slog.Int("n", 1)))
+		expected := `"g":{"key":"value","n":1},`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should inline group with empty key", func(t *testing.T) {
+		encoder := newEncodeJSON(HandlerOptions{})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeAttr(buf, slog.Group("", slog.String("key", "value")))
+		expected := `"key":"value",`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should skip empty group and empty attr", func(t *testing.T) {
+		encoder := newEncodeJSON(HandlerOptions{})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeAttr(buf, slog.Group("g"))
+		encoder.writeAttr(buf, slog.Attr{})
+		if string(*buf) != "" {
+			t.Errorf("Expected buffer to be empty, got '%s'", string(*buf))
+		}
+	})
+}
@@ -1,39 +1,37 @@
 package slogja
 
 import (
-	"fmt"
+	"encoding/hex"
 	"log/slog"
 	"reflect"
 	"strconv"
 	"time"
 )
 
-const (
-	txtGray    = "\033[90m"
-	txtRed     = "\033[31m"
-	txtGreen   = "\033[32m"
-	txtYellow  = "\033[33m"
-	txtBlue    = "\033[34m"
-	txtMagenta = "\033[35m"
-	txtCyan    = "\033[36m"
-	txtWhite   = "\033[37m"
-
-	txtBold  = "\033[1m"
-	txtReset = "\033[0m"
-)
-
 type encodeText struct {
-	opt HandlerOptions
+	opt    HandlerOptions
+	levels levelTable
+	theme  *Theme
+	walk   walkConfig
 }
 
 func newEncodeText(opt HandlerOptions) *encodeText {
-	return &encodeText{
-		opt: opt,
+	theme := opt.Theme
+	if theme == nil {
+		theme = DefaultTheme()
 	}
+
+	e := &encodeText{
+		opt:    opt,
+		levels: newLevelTable(opt.Levels),
+		theme:  theme,
+	}
+	e.walk = newWalkConfig(&e.opt)
+	return e
 }
 
 func (e *encodeText) style(buf *buffer, color string) {
-	if e.opt.DisableColor {
+	if e.opt.DisableColor || color == "" {
 		return
 	}
 	buf.WriteString(color)
@@ -46,31 +44,36 @@ func (e *encodeText) reset(buf *buffer) {
 	buf.WriteString(txtReset)
 }
 
+// endStyle resets the style started by style(buf, color), writing nothing
+// when color was empty.
+func (e *encodeText) endStyle(buf *buffer, color string) {
+	if color == "" {
+		return
+	}
+	e.reset(buf)
+}
+
 func (e *encodeText) writeEmojiLevel(buf *buffer, level slog.Level) {
 	if e.opt.DisableEmoji {
 		return
 	}
 
-	switch level {
-	case slog.LevelError:
-		buf.WriteString("❌ ")
-	case slog.LevelWarn:
-		buf.WriteString("⚠️  ")
-	case slog.LevelInfo:
-		buf.WriteString("🌱 ")
-	case slog.LevelDebug:
-		buf.WriteString("🐛 ")
+	style, _ := e.levels.lookup(level)
+	if style.Emoji == "" {
+		return
 	}
+	buf.WriteString(style.Emoji)
+	buf.WriteByte(' ')
 }
 
 func (e *encodeText) writeTime(buf *buffer, t time.Time) {
-	if e.opt.DisableTime {
+	if e.opt.DisableTime || t.IsZero() {
 		return
 	}
 
-	e.style(buf, txtGray)
+	e.style(buf, e.theme.Time)
 	*buf = t.AppendFormat(*buf, e.opt.TimeFormat)
-	e.reset(buf)
+	e.endStyle(buf, e.theme.Time)
 	buf.WriteByte(' ')
 }
 
@@ -79,32 +82,130 @@ func (e *encodeText) writeLevel(buf *buffer, level slog.Level) {
 		return
 	}
 
-	switch level {
-	case slog.LevelError:
-		e.style(buf, txtRed)
-		buf.WriteString("ERR ")
-		e.reset(buf)
-	case slog.LevelWarn:
-		e.style(buf, txtYellow)
-		buf.WriteString("WRN ")
-		e.reset(buf)
-	case slog.LevelInfo:
-		e.style(buf, txtGreen)
-		buf.WriteString("INF ")
-		e.reset(buf)
-	case slog.LevelDebug:
-		buf.WriteString("DBG ")
+	style, offset := e.levels.lookup(level)
+	color := style.Color
+	if color == "" {
+		color = e.theme.levelColor(level)
+	}
+
+	e.style(buf, color)
+	*buf = appendLevelLabel(*buf, style, offset)
+	buf.WriteByte(' ')
+	e.endStyle(buf, color)
+}
+
+// writeSource writes the source location in the configured SourceFormat. A
+// value replaced by ReplaceAttr is written as is.
+func (e *encodeText) writeSource(buf *buffer, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if src, ok := a.Value.Any().(*slog.Source); ok {
+		e.style(buf, e.theme.Source)
+		*buf = appendSource(*buf, src, e.opt.SourceFormat)
+		e.endStyle(buf, e.theme.Source)
+		e.writeSpace(buf)
+		return
+	}
+
+	e.writeStyledValue(buf, e.theme.Source, a.Value.Resolve())
+}
+
+// writeTimeAttr writes the time attribute returned by ReplaceAttr. The key
+// is not shown; a value that is no longer a time is written as is.
+func (e *encodeText) writeTimeAttr(buf *buffer, a slog.Attr) {
+	if e.opt.DisableTime || a.Equal(slog.Attr{}) {
+		return
+	}
+
+	val := a.Value.Resolve()
+	if val.Kind() == slog.KindTime {
+		e.writeTime(buf, val.Time())
+		return
+	}
+	e.writeStyledValue(buf, e.theme.Time, val)
+}
+
+// writeLevelAttr writes the level attribute returned by ReplaceAttr.
+func (e *encodeText) writeLevelAttr(buf *buffer, a slog.Attr) {
+	if e.opt.DisableLevel || a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if level, ok := a.Value.Any().(slog.Level); ok {
+		e.writeLevel(buf, level)
+		return
+	}
+	e.writeRawValue(buf, a.Value.Resolve())
+	e.writeSpace(buf)
+}
+
+// writeMessageAttr writes the message attribute returned by ReplaceAttr.
+func (e *encodeText) writeMessageAttr(buf *buffer, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	val := a.Value.Resolve()
+	if val.Kind() == slog.KindString {
+		e.writeMessage(buf, val.String())
+		return
 	}
+	e.writeStyledValue(buf, e.theme.Message, val)
+}
 
+func (e *encodeText) writeStyledValue(buf *buffer, color string, val slog.Value) {
+	e.style(buf, color)
+	e.writeRawValue(buf, val)
+	e.endStyle(buf, color)
+	e.writeSpace(buf)
 }
 
+// writeMessage writes the message, cut to MaxStringLength, to the room
+// left by MaxRecordBytes and to the message column.
 func (e *encodeText) writeMessage(buf *buffer, str string) {
-	e.style(buf, txtBold)
-	e.writeString(buf, str)
-	e.reset(buf)
+	writeMessageLimited(e.opt, buf, str, func(buf *buffer, s string) {
+		e.fitMessage(buf, s, e.writeMessageText)
+	})
+}
+
+func (e *encodeText) writeMessageText(buf *buffer, s string) {
+	e.style(buf, e.theme.Message)
+	e.writeQuoted(buf, s)
+	e.endStyle(buf, e.theme.Message)
 	e.writeSpace(buf)
 }
 
+// writeTrace writes the trace attributes in the trace style. The trace and
+// span IDs are shortened to their first 8 hex digits and the flags are left
+// out.
+func (e *encodeText) writeTrace(buf *buffer, attrs []slog.Attr) {
+	for _, a := range attrs {
+		if a.Equal(slog.Attr{}) || a.Key == TraceFlagsKey {
+			continue
+		}
+
+		// The value is written without its own color so that the whole
+		// attribute stays dimmed.
+		s := a.Value.Resolve().String()
+		if (a.Key == TraceIDKey || a.Key == SpanIDKey) && len(s) > 8 {
+			s = s[:8]
+		}
+
+		e.style(buf, e.theme.Trace)
+		*buf = appendEscaped(*buf, a.Key)
+		buf.WriteByte('=')
+		if needsQuoting(s) {
+			*buf = strconv.AppendQuote(*buf, s)
+		} else {
+			buf.WriteString(s)
+		}
+		e.endStyle(buf, e.theme.Trace)
+		e.writeSpace(buf)
+	}
+}
+
 func (e *encodeText) writeAttr(buf *buffer, gs []string, a slog.Attr) {
 	if a.Equal(slog.Attr{}) {
 		return
@@ -112,31 +213,76 @@ func (e *encodeText) writeAttr(buf *buffer, gs []string, a slog.Attr) {
 
 	val := a.Value.Resolve()
 	if val.Kind() == slog.KindGroup {
-		gs = append(gs, a.Key)
-		for _, subAttr := range val.Group() {
+		// A group with an empty key is inlined into its parent. The full
+		// slice expression keeps append from writing into the caller's
+		// backing array, which may be shared by concurrent Handle calls.
+		if a.Key != "" {
+			gs = append(gs[:len(gs):len(gs)], a.Key)
+		}
+		attrs := val.Group()
+		n := limitElements(e.opt.MaxElements, len(attrs))
+		for _, subAttr := range attrs[:n] {
 			e.writeAttr(buf, gs, subAttr)
 		}
-		gs = gs[:len(gs)-1]
+		if n < len(attrs) {
+			e.writeMoreAttr(buf, gs, len(attrs)-n)
+		}
 	} else {
 		e.writeKey(buf, gs, a.Key)
 		e.writeValue(buf, val)
 	}
 }
 
+// writeMoreAttr writes the marker for n members of a group left out by
+// MaxElements as an attribute with "…" as the key.
+func (e *encodeText) writeMoreAttr(buf *buffer, gs []string, n int) {
+	e.writeKey(buf, gs, "…")
+	e.style(buf, e.theme.Separator)
+	e.writeStringer(buf, string(appendMore(nil, n)))
+	e.endStyle(buf, e.theme.Separator)
+	e.writeSpace(buf)
+}
+
+// writeKey writes the dotted key of an attribute. The whole key is quoted
+// when the key or any of its group names would break the line format.
 func (e *encodeText) writeKey(buf *buffer, gs []string, key string) {
-	e.style(buf, txtCyan)
+	e.style(buf, e.theme.Key)
+
+	quote := needsQuoting(key)
 	for _, g := range gs {
-		buf.WriteString(g)
-		buf.WriteByte('.')
+		quote = quote || needsQuoting(g)
 	}
 
-	buf.WriteString(key)
-	e.style(buf, txtGray)
+	if quote {
+		buf.WriteByte('"')
+		for _, g := range gs {
+			*buf = appendQuotedInner(*buf, g)
+			buf.WriteByte('.')
+		}
+		*buf = appendQuotedInner(*buf, key)
+		buf.WriteByte('"')
+	} else {
+		for _, g := range gs {
+			buf.WriteString(g)
+			buf.WriteByte('.')
+		}
+		buf.WriteString(key)
+	}
+
+	e.style(buf, e.theme.Separator)
 	buf.WriteByte('=')
-	e.reset(buf)
+	if e.theme.Key != "" || e.theme.Separator != "" {
+		e.reset(buf)
+	}
 }
 
 func (e *encodeText) writeValue(buf *buffer, val slog.Value) {
+	e.writeRawValue(buf, val)
+	e.writeSpace(buf)
+}
+
+// writeRawValue writes val without the trailing separator.
+func (e *encodeText) writeRawValue(buf *buffer, val slog.Value) {
 	switch val.Kind() {
 	case slog.KindBool:
 		e.writeBool(buf, val.Bool())
@@ -147,79 +293,112 @@ func (e *encodeText) writeValue(buf *buffer, val slog.Value) {
 	case slog.KindFloat64:
 		e.writeFloat(buf, val.Float64())
 	case slog.KindString:
-		e.writeString(buf, val.String())
+		e.writeString(buf, truncateString(val.String(), e.opt.MaxStringLength))
 	case slog.KindTime:
-		e.writeTimeRFC3339(buf, val.Time())
+		e.writeTimeValue(buf, val.Time())
 	case slog.KindDuration:
 		e.writeDuration(buf, val.Duration())
 	case slog.KindAny:
+		if b, ok := e.dumpBytes(val); ok {
+			e.writeDumpPlaceholder(buf, len(b))
+			return
+		}
 		e.writeAny(buf, reflect.ValueOf(val.Any()))
 	}
-	e.writeSpace(buf)
 }
 
 func (e *encodeText) writeAny(buf *buffer, val reflect.Value) {
-	switch val.Kind() {
-	case reflect.Bool:
-		e.writeBool(buf, val.Bool())
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		e.writeInt(buf, val.Int())
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		e.writeUint(buf, val.Uint(), 10)
-	case reflect.Float32, reflect.Float64:
-		e.writeFloat(buf, val.Float())
-	case reflect.String:
-		e.writeString(buf, val.String())
-	case reflect.Struct:
-		if str, ok := val.Interface().(fmt.Stringer); ok {
-			buf.WriteString(str.String())
-			return
-		}
+	writeAny(e, &e.walk, buf, val)
+}
 
+// writeStringer writes the text of a Stringer, TextMarshaler or marker,
+// quoted when it would otherwise break the line format.
+func (e *encodeText) writeStringer(buf *buffer, s string) {
+	if needsQuoting(s) {
+		*buf = strconv.AppendQuote(*buf, s)
+		return
+	}
+	buf.WriteString(s)
+}
+
+// writeError writes the message of an error in the error style. The wrapped
+// chain and stack trace are written by writeDetails below the line.
+func (e *encodeText) writeError(buf *buffer, msg string) {
+	e.style(buf, e.theme.ErrorValue)
+	e.writeQuoted(buf, msg)
+	e.endStyle(buf, e.theme.ErrorValue)
+}
+
+func (e *encodeText) writeNil(buf *buffer) {
+	e.style(buf, e.theme.Nil)
+	buf.WriteString("nil")
+	e.endStyle(buf, e.theme.Nil)
+}
+
+func (e *encodeText) writePointer(buf *buffer, p uintptr) {
+	e.style(buf, e.theme.Number)
+	buf.WriteString("0x")
+	*buf = strconv.AppendUint(*buf, uint64(p), 16)
+	e.endStyle(buf, e.theme.Number)
+}
+
+func (e *encodeText) writeOpen(buf *buffer, kind reflect.Kind) {
+	if kind == reflect.Struct {
 		buf.WriteByte('{')
-		for i := range val.NumField() {
-			field := val.Type().Field(i)
-			if i > 0 {
-				e.writeSpace(buf)
-			}
-			buf.WriteString(field.Name)
-			buf.WriteByte(':')
-			e.writeAny(buf, val.Field(i))
-		}
-		buf.WriteByte('}')
-	case reflect.Slice, reflect.Array:
-		buf.WriteByte('[')
-		for i := range val.Len() {
-			if i > 0 {
-				e.writeSpace(buf)
-			}
-			e.writeAny(buf, val.Index(i))
-		}
-		buf.WriteByte(']')
-	case reflect.Map:
+	} else {
 		buf.WriteByte('[')
-		keys := val.MapKeys()
-		for i, key := range keys {
-			if i > 0 {
-				e.writeSpace(buf)
-			}
-			e.writeAny(buf, key)
-			buf.WriteByte(':')
-			e.writeAny(buf, val.MapIndex(key))
-		}
+	}
+}
+
+func (e *encodeText) writeClose(buf *buffer, kind reflect.Kind) {
+	if kind == reflect.Struct {
+		buf.WriteByte('}')
+	} else {
 		buf.WriteByte(']')
-	case reflect.Ptr:
-		if val.IsNil() {
-			buf.WriteString("nil")
-			return
-		}
-		buf.WriteString("0x")
-		e.writeUint(buf, uint64(val.Pointer()), 16)
-	case reflect.Invalid:
-		buf.WriteString("nil")
 	}
 }
 
+func (e *encodeText) writeSep(buf *buffer) {
+	e.writeSpace(buf)
+}
+
+// writeFieldName writes the name of a struct field or group member. Names
+// from tags, LogValue and renderers are quoted and escaped like keys.
+func (e *encodeText) writeFieldName(buf *buffer, name string) {
+	if needsQuoting(name) {
+		buf.WriteByte('"')
+		*buf = appendQuotedInner(*buf, name)
+		buf.WriteByte('"')
+	} else {
+		buf.WriteString(name)
+	}
+	buf.WriteByte(':')
+}
+
+func (e *encodeText) writeMapKey(buf *buffer, key reflect.Value) {
+	e.writeAny(buf, key)
+	buf.WriteByte(':')
+}
+
+// writeMore writes the marker for n elements left out of a collection.
+func (e *encodeText) writeMore(buf *buffer, kind reflect.Kind, n int) {
+	e.style(buf, e.theme.Separator)
+	*buf = appendMore(*buf, n)
+	e.endStyle(buf, e.theme.Separator)
+}
+
+// writeRawJSON writes embedded JSON, quoted like a Stringer.
+func (e *encodeText) writeRawJSON(buf *buffer, data []byte) {
+	e.writeStringer(buf, string(data))
+}
+
+// writeDump writes bytes nested in another value as hex, since a dump would
+// split the line. The bytes of an attribute are dumped below the line by
+// writeDetails instead.
+func (e *encodeText) writeDump(buf *buffer, b []byte) {
+	e.writeStringer(buf, truncateString(hex.EncodeToString(b), e.opt.MaxStringLength))
+}
+
 func (e *encodeText) writeNewline(buf *buffer) {
 	buf.WriteByte('\n')
 }
@@ -229,37 +408,64 @@ func (e *encodeText) writeSpace(buf *buffer) {
 }
 
 func (e *encodeText) writeString(buf *buffer, s string) {
-	buf.WriteByte('"')
-	buf.WriteString(s)
-	buf.WriteByte('"')
+	e.style(buf, e.theme.String)
+	e.writeQuoted(buf, s)
+	e.endStyle(buf, e.theme.String)
+}
+
+// writeQuoted writes s escaped and, depending on the QuoteMode, quoted.
+func (e *encodeText) writeQuoted(buf *buffer, s string) {
+	if e.opt.Quote == QuoteAuto && !needsQuoting(s) {
+		buf.WriteString(s)
+		return
+	}
+	*buf = strconv.AppendQuote(*buf, s)
 }
 
 func (e *encodeText) writeBool(buf *buffer, b bool) {
+	e.style(buf, e.theme.Bool)
 	if b {
 		buf.WriteString("true")
 	} else {
 		buf.WriteString("false")
 	}
+	e.endStyle(buf, e.theme.Bool)
 }
 
 func (e *encodeText) writeInt(buf *buffer, i int64) {
+	e.style(buf, e.theme.Number)
 	*buf = strconv.AppendInt(*buf, i, 10)
+	e.endStyle(buf, e.theme.Number)
 }
 
 func (e *encodeText) writeUint(buf *buffer, u uint64, base int) {
+	e.style(buf, e.theme.Number)
 	*buf = strconv.AppendUint(*buf, u, base)
+	e.endStyle(buf, e.theme.Number)
 }
 
 func (e *encodeText) writeFloat(buf *buffer, f float64) {
+	e.style(buf, e.theme.Number)
 	*buf = strconv.AppendFloat(*buf, f, 'g', -1, 64)
+	e.endStyle(buf, e.theme.Number)
 }
 
 func (e *encodeText) writeDuration(buf *buffer, d time.Duration) {
-	*buf = strconv.AppendInt(*buf, int64(d), 10)
+	e.style(buf, e.theme.Duration)
+	*buf = appendDuration(*buf, d, e.opt.DurationFormat, e.opt.DurationUnit)
+	e.endStyle(buf, e.theme.Duration)
 }
 
-func (e *encodeText) writeTimeRFC3339(buf *buffer, t time.Time) {
-	*buf = appendRFC3339Millis(*buf, t)
+// writeTimeValue writes a time-valued attribute in the TimeAttrFormat,
+// quoted if the format produces spaces, as in "3m ago".
+func (e *encodeText) writeTimeValue(buf *buffer, t time.Time) {
+	e.style(buf, e.theme.TimeValue)
+	start := len(*buf)
+	*buf = appendTimeAttr(*buf, t, e.opt.TimeAttrFormat, e.opt.TimeFormat)
+	if s := string((*buf)[start:]); needsQuoting(s) {
+		*buf = strconv.AppendQuote((*buf)[:start], s)
+	}
+	e.endStyle(buf, e.theme.TimeValue)
 }
 
 // copy from log/slog/handler.go
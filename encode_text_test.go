@@ -361,7 +361,7 @@ func TestWriteData(t *testing.T) {
 
 		// Test writing Time Value data
 		testTime := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
-		encoder.writeTimeRFC3339(buf, testTime)
+		encoder.writeTimeValue(buf, testTime)
 		expected := "2023-10-01T12:00:00.000Z"
 		if string(*buf) != expected {
 			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
@@ -476,7 +476,7 @@ func TestWriteAny(t *testing.T) {
 		testTime := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
 		val := reflect.ValueOf(testTime)
 		encoder.writeAny(buf, val)
-		expected := "2023-10-01 12:00:00 +0000 UTC"
+		expected := "2023-10-01T12:00:00.000Z"
 		if string(*buf) != expected {
 			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
 		}
@@ -500,7 +500,7 @@ func TestWriteAny(t *testing.T) {
 		}
 		val := reflect.ValueOf(testStruct)
 		encoder.writeAny(buf, val)
-		expected := "{Name:\"Test Struct\" Age:30 privateField:\"This should not be visible\"}"
+		expected := "{Name:\"Test Struct\" Age:30}"
 		if string(*buf) != expected {
 			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
 		}
@@ -516,7 +516,7 @@ func TestWriteAny(t *testing.T) {
 		testSlice := []Data{{Value: "Test Slice 1"}, {Value: "Test Slice 2"}}
 		val := reflect.ValueOf(testSlice)
 		encoder.writeAny(buf, val)
-		expected := "[Test Slice 1 Test Slice 2]"
+		expected := `["Test Slice 1" "Test Slice 2"]`
 		if string(*buf) != expected {
 			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
 		}
@@ -551,18 +551,34 @@ func TestWriteAny(t *testing.T) {
 
 		val := reflect.ValueOf(testInterface)
 		encoder.writeAny(buf, val)
-		expected := testInterface.String()
+		expected := `"Test Interface"`
 		if string(*buf) != expected {
 			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
 		}
 	})
 
-	t.Run("should write ptr when reflect.TypeOf is pointer", func(t *testing.T) {
+	t.Run("should write pointed to value when reflect.TypeOf is pointer", func(t *testing.T) {
 		opt := HandlerOptions{}
 		encoder := newEncodeText(opt)
 		buf := newBuffer()
 		defer buf.Free()
 
+		// Test writing pointer value
+		testPtr := &Data{Value: "Test Pointer"}
+		val := reflect.ValueOf(testPtr)
+		encoder.writeAny(buf, val)
+		expected := `"Test Pointer"`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should write ptr when reflect.TypeOf is pointer and Pointer is PointerAddress", func(t *testing.T) {
+		opt := HandlerOptions{Pointer: PointerAddress}
+		encoder := newEncodeText(opt)
+		buf := newBuffer()
+		defer buf.Free()
+
 		// Test writing pointer value
 		testPtr := &Data{Value: "Test Pointer"}
 		val := reflect.ValueOf(testPtr)
@@ -825,3 +841,74 @@ func TestWriteAttr(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteStringQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     QuoteMode
+		input    string
+		expected string
+	}{
+		{name: "should always quote plain string", mode: QuoteAlways, input: "value", expected: `"value"`},
+		{name: "should escape quotes", mode: QuoteAlways, input: `say "hi"`, expected: `"say \"hi\""`},
+		{name: "should escape newline injection", mode: QuoteAlways, input: "ok\nERR fake=1", expected: `"ok\nERR fake=1"`},
+		{name: "should escape terminal sequences", mode: QuoteAlways, input: "\x1b[2J\x1b[31mred", expected: `"\x1b[2J\x1b[31mred"`},
+		{name: "should escape control bytes", mode: QuoteAlways, input: "a\x00\x07b", expected: `"a\x00\ab"`},
+		{name: "should not quote plain string when auto", mode: QuoteAuto, input: "value", expected: "value"},
+		{name: "should quote empty string when auto", mode: QuoteAuto, input: "", expected: `""`},
+		{name: "should quote string with space when auto", mode: QuoteAuto, input: "a b", expected: `"a b"`},
+		{name: "should quote string with equals when auto", mode: QuoteAuto, input: "a=b", expected: `"a=b"`},
+		{name: "should quote terminal sequences when auto", mode: QuoteAuto, input: "\x1b[31m", expected: `"\x1b[31m"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := newEncodeText(HandlerOptions{Quote: tt.mode})
+			buf := newBuffer()
+			defer buf.Free()
+
+			encoder.writeString(buf, tt.input)
+			if string(*buf) != tt.expected {
+				t.Errorf("Expected buffer to contain '%s', got '%s'", tt.expected, string(*buf))
+			}
+		})
+	}
+}
+
+func TestWriteKeyQuote(t *testing.T) {
+	t.Run("should quote key with space", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{DisableColor: true})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeKey(buf, []string{"group"}, "my key")
+		expected := `"group.my key"=`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should quote group name with terminal sequence", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{DisableColor: true})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeKey(buf, []string{"\x1b[31m"}, "key")
+		expected := `"\x1b[31m.key"=`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+}
+
+func TestWriteStringerEscape(t *testing.T) {
+	encoder := newEncodeText(HandlerOptions{})
+	buf := newBuffer()
+	defer buf.Free()
+
+	encoder.writeAny(buf, reflect.ValueOf(Data{Value: "line\n\x1b[31mred"}))
+	expected := `"line\n\x1b[31mred"`
+	if string(*buf) != expected {
+		t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+	}
+}
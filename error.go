@@ -0,0 +1,198 @@
+package slogja
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxErrorChain bounds how many wrapped errors are expanded, so an error
+// that unwraps to itself cannot loop forever.
+const maxErrorChain = 32
+
+var errorType = reflect.TypeFor[error]()
+
+// errorStack returns the program counters of the innermost error in the
+// Unwrap chain that exposes a StackTrace method. Both StackTrace() []uintptr
+// and the github.com/pkg/errors form, a slice of uintptr based frames, are
+// recognised.
+func errorStack(err error) (pcs []uintptr) {
+	// A panicking Unwrap or StackTrace ends the search with what was found.
+	defer func() { _ = recover() }()
+
+	for i := 0; err != nil && i < maxErrorChain; i++ {
+		if p := stackPCs(err); p != nil {
+			pcs = p
+		}
+		err = errors.Unwrap(err)
+	}
+	return pcs
+}
+
+func stackPCs(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
+
+// hasErrorDetails reports whether err has anything to show below the log
+// line with the given options.
+func (e *encodeText) hasErrorDetails(err error) bool {
+	switch err.(type) {
+	case interface{ Unwrap() error }, interface{ Unwrap() []error }:
+		if e.opt.ErrorChain {
+			return true
+		}
+	}
+	return e.opt.ErrorStack && len(errorStack(err)) > 0
+}
+
+// appendDetailAttrs collects the attributes in a, including those nested
+// in groups, with details to write below the log line: errors whose chain
+// or stack is shown and bytes dumped by BytesHexdump. The returned
+// attributes carry the full dotted key.
+func (e *encodeText) appendDetailAttrs(dst []slog.Attr, gs []string, a slog.Attr) []slog.Attr {
+	if !e.opt.ErrorChain && !e.opt.ErrorStack && e.opt.Bytes != BytesHexdump {
+		return dst
+	}
+
+	val := a.Value.Resolve()
+	switch val.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			gs = append(gs[:len(gs):len(gs)], a.Key)
+		}
+		// Members left out by MaxElements have no details either.
+		attrs := val.Group()
+		for _, subAttr := range attrs[:limitElements(e.opt.MaxElements, len(attrs))] {
+			dst = e.appendDetailAttrs(dst, gs, subAttr)
+		}
+	case slog.KindAny:
+		_, dump := e.dumpBytes(val)
+		err, ok := val.Any().(error)
+		if dump || (ok && e.hasErrorDetails(err)) {
+			key := a.Key
+			if len(gs) > 0 {
+				key = strings.Join(gs, ".") + "." + key
+			}
+			dst = append(dst, slog.Attr{Key: key, Value: val})
+		}
+	}
+	return dst
+}
+
+// writeDetails writes the hex dump, or the wrapped errors and the stack
+// trace, of an attribute collected by appendDetailAttrs as an indented
+// block.
+func (e *encodeText) writeDetails(buf *buffer, a slog.Attr) {
+
+	buf.WriteString("  ")
+	e.style(buf, e.theme.Key)
+	*buf = appendEscaped(*buf, a.Key)
+	e.style(buf, e.theme.Separator)
+	buf.WriteByte(':')
+	if e.theme.Key != "" || e.theme.Separator != "" {
+		e.reset(buf)
+	}
+	e.writeNewline(buf)
+
+	if b, ok := e.dumpBytes(a.Value); ok {
+		e.writeDumpLines(buf, b)
+		return
+	}
+
+	err, _ := a.Value.Any().(error)
+	if e.opt.ErrorChain {
+		n := 0
+		e.writeSafeErrorChain(buf, err, &n)
+	}
+
+	if e.opt.ErrorStack {
+		frames := runtime.CallersFrames(errorStack(err))
+		for {
+			f, more := frames.Next()
+			if f.Function != "" || f.File != "" {
+				e.writeIndent(buf, 2)
+				e.style(buf, e.theme.Separator)
+				buf.WriteString("at ")
+				buf.WriteString(f.Function)
+				buf.WriteString(" (")
+				*buf = appendSource(*buf, &slog.Source{File: f.File, Line: f.Line}, SourceShort)
+				buf.WriteByte(')')
+				e.endStyle(buf, e.theme.Separator)
+				e.writeNewline(buf)
+			}
+			if !more {
+				break
+			}
+		}
+	}
+}
+
+// writeSafeErrorChain writes the chain of err, ending it with a !PANIC line
+// if an Unwrap method panics.
+func (e *encodeText) writeSafeErrorChain(buf *buffer, err error, n *int) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.writeIndent(buf, 2)
+			e.style(buf, e.theme.ErrorValue)
+			*buf = appendEscaped(*buf, panicMarker("Unwrap", r))
+			e.endStyle(buf, e.theme.ErrorValue)
+			e.writeNewline(buf)
+		}
+	}()
+	e.writeErrorChain(buf, err, 2, n)
+}
+
+// writeErrorChain writes one line per wrapped error. Errors joined with
+// errors.Join are listed at the same depth and their own chains are
+// indented one level deeper.
+func (e *encodeText) writeErrorChain(buf *buffer, err error, depth int, n *int) {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, child := range u.Unwrap() {
+			if child != nil && *n < maxErrorChain {
+				e.writeErrorLine(buf, child, depth, n)
+				e.writeErrorChain(buf, child, depth+1, n)
+			}
+		}
+	case interface{ Unwrap() error }:
+		if child := u.Unwrap(); child != nil && *n < maxErrorChain {
+			e.writeErrorLine(buf, child, depth, n)
+			e.writeErrorChain(buf, child, depth, n)
+		}
+	}
+}
+
+func (e *encodeText) writeErrorLine(buf *buffer, err error, depth int, n *int) {
+	*n++
+	e.writeIndent(buf, depth)
+	e.style(buf, e.theme.Separator)
+	buf.WriteString("↳ ")
+	e.endStyle(buf, e.theme.Separator)
+	e.style(buf, e.theme.ErrorValue)
+	*buf = appendEscaped(*buf, errorMessage(err))
+	e.endStyle(buf, e.theme.ErrorValue)
+	e.writeNewline(buf)
+}
+
+func (e *encodeText) writeIndent(buf *buffer, depth int) {
+	for range depth {
+		buf.WriteString("  ")
+	}
+}
@@ -0,0 +1,190 @@
+package slogja
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newStackError(msg string) *stackError {
+	pcs := make([]uintptr, 1)
+	runtime.Callers(2, pcs)
+	return &stackError{msg: msg, pcs: pcs}
+}
+
+func (e *stackError) Error() string {
+	return e.msg
+}
+
+func (e *stackError) StackTrace() []uintptr {
+	return e.pcs
+}
+
+// frame mimics github.com/pkg/errors.Frame.
+type frame uintptr
+
+type pkgStackError struct {
+	stack []frame
+}
+
+func (e pkgStackError) Error() string {
+	return "pkg error"
+}
+
+func (e pkgStackError) StackTrace() []frame {
+	return e.stack
+}
+
+type codeError struct {
+	Code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("code %d", e.Code)
+}
+
+func TestErrorStack(t *testing.T) {
+	t.Run("should return stack of innermost error", func(t *testing.T) {
+		inner := newStackError("inner")
+		outer := fmt.Errorf("outer: %w", inner)
+
+		pcs := errorStack(outer)
+		if len(pcs) != 1 || pcs[0] != inner.pcs[0] {
+			t.Errorf("Expected stack of inner error, got '%v'", pcs)
+		}
+	})
+
+	t.Run("should read pkg/errors style stack", func(t *testing.T) {
+		err := pkgStackError{stack: []frame{1, 2}}
+
+		pcs := errorStack(err)
+		if !reflect.DeepEqual(pcs, []uintptr{1, 2}) {
+			t.Errorf("Expected stack [1 2], got '%v'", pcs)
+		}
+	})
+
+	t.Run("should return nil when error has no stack", func(t *testing.T) {
+		if pcs := errorStack(errors.New("plain")); pcs != nil {
+			t.Errorf("Expected nil stack, got '%v'", pcs)
+		}
+	})
+}
+
+func TestWriteError(t *testing.T) {
+	t.Run("should write error message in red", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeValue(buf, slog.AnyValue(&codeError{Code: 404}))
+		expected := txtRed + `"code 404"` + txtReset + " "
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should write error nested in struct", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{DisableColor: true})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeAny(buf, reflect.ValueOf(struct {
+			Err  error
+			None error
+		}{Err: errors.New("boom")}))
+		expected := `{Err:"boom" None:nil}`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should write error message in json", func(t *testing.T) {
+		encoder := newEncodeJSON(HandlerOptions{})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeValue(buf, slog.AnyValue(&codeError{Code: 500}))
+		expected := `"code 500",`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+}
+
+func TestWriteErrorDetails(t *testing.T) {
+	t.Run("should write wrapped chain", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{DisableColor: true, ErrorChain: true})
+		buf := newBuffer()
+		defer buf.Free()
+
+		err := fmt.Errorf("load config: %w", fmt.Errorf("open cfg.yaml: %w", errors.New("permission denied")))
+		attrs := encoder.appendDetailAttrs(nil, []string{"req"}, slog.Any("err", err))
+		if len(attrs) != 1 {
+			t.Fatalf("Expected one error attr, got %d", len(attrs))
+		}
+
+		encoder.writeDetails(buf, attrs[0])
+		expected := "  req.err:\n" +
+			"    ↳ open cfg.yaml: permission denied\n" +
+			"    ↳ permission denied\n"
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should indent joined errors", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{DisableColor: true, ErrorChain: true})
+		buf := newBuffer()
+		defer buf.Free()
+
+		err := errors.Join(errors.New("first"), fmt.Errorf("second: %w", errors.New("inner")))
+		encoder.writeDetails(buf, slog.Any("err", err))
+		expected := "  err:\n" +
+			"    ↳ first\n" +
+			"    ↳ second: inner\n" +
+			"      ↳ inner\n"
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should write stack trace", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{DisableColor: true, ErrorStack: true})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeDetails(buf, slog.Any("err", newStackError("boom")))
+		if !strings.Contains(string(*buf), "    at github.com/kongsakchai/slogja.TestWriteErrorDetails") ||
+			!strings.Contains(string(*buf), "error_test.go:") {
+			t.Errorf("Expected buffer to contain stack trace, got '%s'", string(*buf))
+		}
+	})
+
+	t.Run("should skip errors without details", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{ErrorChain: true, ErrorStack: true})
+
+		attrs := encoder.appendDetailAttrs(nil, nil, slog.Any("err", errors.New("plain")))
+		if len(attrs) != 0 {
+			t.Errorf("Expected no error attrs, got '%v'", attrs)
+		}
+	})
+
+	t.Run("should skip group members left out by MaxElements", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{ErrorChain: true, MaxElements: 1})
+
+		err := fmt.Errorf("wrap: %w", errors.New("inner"))
+		attrs := encoder.appendDetailAttrs(nil, nil, slog.Group("g", slog.Any("a", err), slog.Any("b", err)))
+		if len(attrs) != 1 || attrs[0].Key != "g.a" {
+			t.Errorf("Expected only g.a, got '%v'", attrs)
+		}
+	})
+}
@@ -46,15 +46,25 @@ func main() {
 
 	print(d)
 
-	l := slog.New(slogja.NewTextHandler(
-		os.Stdout,
-		&slogja.HandlerOptions{
-			Level:      slog.LevelDebug,
-			TimeFormat: "2006-01-02 15:04:05",
-		},
-	))
+	opts := &slogja.HandlerOptions{
+		Level:      slog.LevelDebug,
+		TimeFormat: "2006-01-02 15:04:05",
+		Color:      slogja.ColorAuto,
+	}
+
+	l := slog.New(slogja.NewTextHandler(os.Stdout, opts))
 
 	print(l)
+
+	pretty := *opts
+	pretty.Pretty = slogja.PrettyAuto
+	p := slog.New(slogja.NewTextHandler(os.Stdout, &pretty))
+
+	print(p)
+
+	j := slog.New(slogja.NewJSONHandler(os.Stdout, opts))
+
+	print(j)
 }
 
 func print(l *slog.Logger) {
@@ -0,0 +1,128 @@
+package slogja
+
+import (
+	"strconv"
+	"time"
+)
+
+// DurationFormat controls how time.Duration values are written.
+type DurationFormat int
+
+const (
+	// DurationNanos writes the number of nanoseconds, e.g. 1500000000.
+	DurationNanos DurationFormat = iota
+	// DurationString writes the form of time.Duration.String, e.g. "1.5s".
+	DurationString
+	// DurationSeconds writes the number of seconds as a float, e.g. 1.5.
+	DurationSeconds
+	// DurationFixed writes the duration as a float count of
+	// HandlerOptions.DurationUnit, e.g. 1500 for time.Millisecond.
+	DurationFixed
+)
+
+// TimeAttrFormat controls how time.Time values in attributes are written.
+// The record time in the header always uses HandlerOptions.TimeFormat.
+type TimeAttrFormat int
+
+const (
+	// TimeAttrRFC3339Millis writes RFC 3339 with milliseconds, like slog.
+	TimeAttrRFC3339Millis TimeAttrFormat = iota
+	// TimeAttrHeader writes times like the record time in the header.
+	TimeAttrHeader
+	// TimeAttrRFC3339Nano writes time.RFC3339Nano.
+	TimeAttrRFC3339Nano
+	// TimeAttrUnix writes the number of seconds since the Unix epoch.
+	TimeAttrUnix
+	// TimeAttrUnixMilli writes the number of milliseconds since the Unix
+	// epoch.
+	TimeAttrUnixMilli
+	// TimeAttrRelative writes the time relative to now, e.g. "3m ago" or
+	// "in 2h".
+	TimeAttrRelative
+)
+
+// timeNow is replaced in tests of TimeAttrRelative.
+var timeNow = time.Now
+
+// appendDuration appends d in the format f.
+func appendDuration(b []byte, d time.Duration, f DurationFormat, unit time.Duration) []byte {
+	switch f {
+	case DurationString:
+		return append(b, d.String()...)
+	case DurationSeconds:
+		return strconv.AppendFloat(b, d.Seconds(), 'g', -1, 64)
+	case DurationFixed:
+		if unit <= 0 {
+			unit = time.Millisecond
+		}
+		return strconv.AppendFloat(b, float64(d)/float64(unit), 'g', -1, 64)
+	default:
+		return strconv.AppendInt(b, int64(d), 10)
+	}
+}
+
+// durationIsString reports whether durations in the format f are quoted in
+// JSON.
+func durationIsString(f DurationFormat) bool {
+	return f == DurationString
+}
+
+// appendTimeAttr appends t in the format f. headerFormat is the layout of
+// the header, RFC 3339 with milliseconds if empty.
+func appendTimeAttr(b []byte, t time.Time, f TimeAttrFormat, headerFormat string) []byte {
+	switch f {
+	case TimeAttrHeader:
+		if headerFormat != "" {
+			return t.AppendFormat(b, headerFormat)
+		}
+	case TimeAttrRFC3339Nano:
+		return t.AppendFormat(b, time.RFC3339Nano)
+	case TimeAttrUnix:
+		return strconv.AppendInt(b, t.Unix(), 10)
+	case TimeAttrUnixMilli:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	case TimeAttrRelative:
+		return appendRelative(b, timeNow().Sub(t))
+	}
+	return appendRFC3339Millis(b, t)
+}
+
+// timeAttrIsString reports whether times in the format f are quoted in JSON.
+func timeAttrIsString(f TimeAttrFormat) bool {
+	return f != TimeAttrUnix && f != TimeAttrUnixMilli
+}
+
+// appendRelative appends d, the time elapsed since an event, rounded down to
+// its largest unit: "just now", "42s ago", "3m ago", "5h ago", "2d ago", or
+// "in 3m" for an event in the future.
+func appendRelative(b []byte, d time.Duration) []byte {
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Second {
+		return append(b, "just now"...)
+	}
+
+	if future {
+		b = append(b, "in "...)
+	}
+	switch {
+	case d < time.Minute:
+		b = strconv.AppendInt(b, int64(d/time.Second), 10)
+		b = append(b, 's')
+	case d < time.Hour:
+		b = strconv.AppendInt(b, int64(d/time.Minute), 10)
+		b = append(b, 'm')
+	case d < 24*time.Hour:
+		b = strconv.AppendInt(b, int64(d/time.Hour), 10)
+		b = append(b, 'h')
+	default:
+		b = strconv.AppendInt(b, int64(d/(24*time.Hour)), 10)
+		b = append(b, 'd')
+	}
+	if !future {
+		b = append(b, " ago"...)
+	}
+	return b
+}
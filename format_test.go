@@ -0,0 +1,137 @@
+package slogja
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAppendDuration(t *testing.T) {
+	d := 1500 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		format   DurationFormat
+		unit     time.Duration
+		expected string
+	}{
+		{name: "should write nanoseconds by default", format: DurationNanos, expected: "1500000000"},
+		{name: "should write Go string form", format: DurationString, expected: "1.5s"},
+		{name: "should write float seconds", format: DurationSeconds, expected: "1.5"},
+		{name: "should write milliseconds for fixed without unit", format: DurationFixed, expected: "1500"},
+		{name: "should write fixed unit", format: DurationFixed, unit: time.Minute, expected: "0.025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(appendDuration(nil, d, tt.format, tt.unit)); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAppendTimeAttr(t *testing.T) {
+	tm := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC)
+
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return tm.Add(3*time.Minute + 10*time.Second) }
+
+	tests := []struct {
+		name     string
+		format   TimeAttrFormat
+		header   string
+		expected string
+	}{
+		{name: "should write RFC3339 millis by default", format: TimeAttrRFC3339Millis, expected: "2024-03-01T12:30:00.123Z"},
+		{name: "should write header format", format: TimeAttrHeader, header: time.Kitchen, expected: "12:30PM"},
+		{name: "should fall back to RFC3339 millis without header format", format: TimeAttrHeader, expected: "2024-03-01T12:30:00.123Z"},
+		{name: "should write RFC3339 nano", format: TimeAttrRFC3339Nano, expected: "2024-03-01T12:30:00.123456789Z"},
+		{name: "should write unix seconds", format: TimeAttrUnix, expected: "1709296200"},
+		{name: "should write unix milliseconds", format: TimeAttrUnixMilli, expected: "1709296200123"},
+		{name: "should write relative time", format: TimeAttrRelative, expected: "3m ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(appendTimeAttr(nil, tm, tt.format, tt.header)); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAppendRelative(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{d: 500 * time.Millisecond, expected: "just now"},
+		{d: 42 * time.Second, expected: "42s ago"},
+		{d: 3*time.Minute + 59*time.Second, expected: "3m ago"},
+		{d: 5 * time.Hour, expected: "5h ago"},
+		{d: 50 * time.Hour, expected: "2d ago"},
+		{d: -2 * time.Hour, expected: "in 2h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := string(appendRelative(nil, tt.d)); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandlerDurationAndTimeFormats(t *testing.T) {
+	tm := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	opts := HandlerOptions{
+		DisableColor:   true,
+		DisableEmoji:   true,
+		DisableTime:    true,
+		TimeFormat:     time.DateTime,
+		DurationFormat: DurationString,
+		TimeAttrFormat: TimeAttrHeader,
+	}
+
+	t.Run("should format attributes and nested values in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		slog.New(NewTextHandler(b, &opts)).Info("msg",
+			"d", 1500*time.Millisecond,
+			"t", tm,
+			"nested", struct {
+				D time.Duration
+				T time.Time
+			}{D: time.Minute, T: tm},
+		)
+
+		expected := `INF "msg" d=1.5s t="2024-03-01 12:30:00" nested={D:1m0s T:"2024-03-01 12:30:00"} ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should quote string forms in json handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		slog.New(NewJSONHandler(b, &opts)).Info("msg", "d", 1500*time.Millisecond, "t", []time.Time{tm})
+
+		expected := `{"level":"INFO","msg":"msg","d":"1.5s","t":["2024-03-01 12:30:00"]}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should write numbers in json handler", func(t *testing.T) {
+		buf := newBuffer()
+		defer buf.Free()
+
+		e := newEncodeJSON(HandlerOptions{DurationFormat: DurationSeconds, TimeAttrFormat: TimeAttrUnix})
+		e.writeAny(buf, reflect.ValueOf([]any{2 * time.Second, tm}))
+		expected := `[2,1709296200]`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+}
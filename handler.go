@@ -4,28 +4,185 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 )
 
 type replaceAttrFunc func(groups []string, a slog.Attr) slog.Attr
 
+// replaceAttr resolves a and passes it through rep with the contract of
+// log/slog: rep is never called for a group itself, only for its members
+// with the group appended to gs, and members replaced by an empty Attr are
+// dropped.
+func replaceAttr(rep replaceAttrFunc, gs []string, a slog.Attr) slog.Attr {
+	if rep == nil {
+		return a
+	}
+
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		return rep(gs, a)
+	}
+
+	attrs := a.Value.Group()
+	if a.Key != "" {
+		gs = append(gs[:len(gs):len(gs)], a.Key)
+	}
+
+	replaced := make([]slog.Attr, 0, len(attrs))
+	for _, subAttr := range attrs {
+		if ra := replaceAttr(rep, gs, subAttr); !ra.Equal(slog.Attr{}) {
+			replaced = append(replaced, ra)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(replaced...)}
+}
+
 type HandlerOptions struct {
-	Level        slog.Level
+	// Level reports the minimum level to log. It is consulted on every
+	// call, so a *slog.LevelVar changes the level of the handler and all
+	// handlers derived from it at runtime. If nil, slog.LevelInfo is used.
+	Level        slog.Leveler
 	ReplaceAttr  replaceAttrFunc
 	TimeFormat   string
 	DisableColor bool
 	DisableEmoji bool
 	DisableTime  bool
 	DisableLevel bool
+
+	// DurationFormat selects how durations are written, and DurationUnit
+	// the unit of DurationFixed, time.Millisecond if zero.
+	DurationFormat DurationFormat
+	DurationUnit   time.Duration
+	// TimeAttrFormat selects how time-valued attributes are written.
+	TimeAttrFormat TimeAttrFormat
+
+	// AddSource adds the file and line of the log call to the output.
+	AddSource bool
+	// SourceFormat selects how the text handler renders the source.
+	SourceFormat SourceFormat
+
+	// Levels maps level ranges to the label, color and emoji used by the
+	// text handler, and to the level name written by the JSON handler. If
+	// empty, DBG, INF, WRN and ERR are used.
+	Levels []LevelStyle
+
+	// Color selects when the text handler writes colors. The zero value,
+	// ColorDefault, keeps colors on unless DisableColor, NO_COLOR or
+	// FORCE_COLOR turns them off.
+	Color ColorMode
+	// IsTerminal replaces the terminal check used by ColorAuto.
+	IsTerminal func(w io.Writer) bool
+
+	// Theme sets the colors of the text handler. If nil, DefaultTheme is
+	// used.
+	Theme *Theme
+
+	// Layout selects how the text handler lays out the start of a line. In
+	// LayoutColumns the level, source and message are padded to
+	// LevelWidth, SourceWidth and MessageWidth cells, by default the
+	// widest level label, 24 and 40, and MessageOverflow selects what
+	// happens to longer messages. Longer levels, such as "WRN+2", are
+	// written whole.
+	Layout          LayoutMode
+	LevelWidth      int
+	SourceWidth     int
+	MessageWidth    int
+	MessageOverflow OverflowMode
+
+	// Pretty selects when the text handler writes attributes as a tree
+	// below the message line, one per line. PrettyAuto does so for lines
+	// wider than PrettyWidth cells, 120 if zero.
+	Pretty      PrettyMode
+	PrettyWidth int
+
+	// Wrap selects what the text handler does with lines wider than Width
+	// cells. If Width is zero, it is taken from COLUMNS or the terminal
+	// the handler writes to when the handler is created, or 80.
+	Wrap  WrapMode
+	Width int
+
+	// ContextExtractors add attributes taken from the context passed to
+	// Handle, after the record's own attributes and within the current
+	// groups. Attributes added with ContextWithAttrs are always included.
+	ContextExtractors []ContextExtractor
+
+	// Trace adds the trace and span IDs of the context passed to Handle.
+	// The text handler shows them dimmed and shortened after the message;
+	// the JSON handler writes trace_id, span_id and trace_flags in full.
+	Trace TraceProvider
+
+	// Quote selects when the text handler quotes strings.
+	Quote QuoteMode
+
+	// Pointer selects whether pointers are followed or written as their
+	// address.
+	Pointer PointerMode
+
+	// MaxDepth limits how deeply collections nested in a value are
+	// written; deeper ones are replaced by "…". Zero means no limit, here
+	// and for the limits below.
+	MaxDepth int
+	// MaxElements limits the elements written per slice, array, map or
+	// group; the rest are replaced by a marker such as "…(+120 more)".
+	MaxElements int
+	// MaxStringLength limits the message and string values to this many
+	// bytes.
+	MaxStringLength int
+	// MaxRecordBytes limits the bytes taken by the message and the
+	// attributes of a record on its line. The message is cut to fit after
+	// the time, level, source and trace, and attributes that would exceed
+	// it are dropped and counted in a final "truncated" attribute, whose
+	// value is the number dropped. It is not a bound on the record size:
+	// attributes added with WithAttrs are always written in full and count
+	// toward the limit, and the error details and hex dumps written below
+	// the line are not counted.
+	MaxRecordBytes int
+
+	// Bytes selects how []byte and [N]byte values are written, and
+	// BytesDumpLimit the number of bytes shown by BytesHexdump, 256 if
+	// zero.
+	Bytes          BytesFormat
+	BytesDumpLimit int
+
+	// Renderers write the values of the given types, wherever they appear,
+	// as the slog.Value they return.
+	Renderers []TypeRenderer
+
+	// UnsortedMapKeys writes map entries in Go's random iteration order
+	// instead of sorting the keys, which saves the sort on hot paths.
+	UnsortedMapKeys bool
+
+	// JSONTags makes struct fields without a slogja tag use their json tag
+	// for the key name, omitempty and "-".
+	JSONTags bool
+
+	// ErrorChain lists the errors wrapped by an error attribute, following
+	// errors.Unwrap and errors.Join, in an indented block below the line.
+	ErrorChain bool
+	// ErrorStack writes the stack trace of an error attribute below the
+	// line when the error exposes a StackTrace method.
+	ErrorStack bool
+}
+
+func minLevel(l slog.Leveler) slog.Level {
+	if l == nil {
+		return slog.LevelInfo
+	}
+	return l.Level()
 }
 
 type textHandler struct {
-	opts       HandlerOptions
-	attrPrefix []byte
-	groups     []string
+	opts        HandlerOptions
+	attrPrefix  []byte
+	groups      []string
+	detailAttrs []slog.Attr
 
-	mu sync.Mutex
+	// prettyAttrs holds the attributes of attrPrefix for PrettyMode.
+	prettyAttrs []groupedAttr
+
+	mu *sync.Mutex // shared by all handlers derived from the same root
 	w  io.Writer
 	en *encodeText
 }
@@ -35,32 +192,50 @@ func NewTextHandler(w io.Writer, opts *HandlerOptions) *textHandler {
 		opts = &HandlerOptions{
 			Level:      slog.LevelInfo,
 			TimeFormat: time.RFC3339,
+			Color:      ColorAuto,
 		}
 	}
 
+	o := *opts
+	o.DisableColor = !useColor(w, o)
+	if o.Wrap != WrapOff {
+		o.Width = lineWidth(w, o)
+	}
+
 	return &textHandler{
-		opts:   *opts,
+		opts:   o,
+		mu:     &sync.Mutex{},
 		w:      w,
 		groups: make([]string, 0, 5),
-		en:     newEncodeText(*opts),
+		en:     newEncodeText(o),
 	}
 }
 
 func (h *textHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.opts.Level
+	return level >= minLevel(h.opts.Level)
 }
 
 func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	buf := newBuffer()
+	buf := buffer(slices.Clone(h.attrPrefix))
+	detailAttrs := slices.Clip(h.detailAttrs)
+	prettyAttrs := slices.Clip(h.prettyAttrs)
 	for _, a := range attrs {
-		h.en.writeAttr(buf, h.groups, a)
+		a = replaceAttr(h.opts.ReplaceAttr, h.groups, a)
+		h.en.writeAttr(&buf, h.groups, a)
+		detailAttrs = h.en.appendDetailAttrs(detailAttrs, h.groups, a)
+		if h.opts.Pretty != PrettyOff {
+			prettyAttrs = append(prettyAttrs, groupedAttr{groups: h.groups, attr: a})
+		}
 	}
 	return &textHandler{
-		opts:       h.opts,
-		w:          h.w,
-		groups:     h.groups,
-		en:         h.en,
-		attrPrefix: *buf,
+		opts:        h.opts,
+		mu:          h.mu,
+		w:           h.w,
+		groups:      h.groups,
+		en:          h.en,
+		attrPrefix:  buf,
+		detailAttrs: detailAttrs,
+		prettyAttrs: prettyAttrs,
 	}
 }
 
@@ -72,11 +247,14 @@ func (h *textHandler) WithGroup(name string) slog.Handler {
 	gs[len(gs)-1] = name
 
 	return &textHandler{
-		opts:       h.opts,
-		w:          h.w,
-		attrPrefix: h.attrPrefix,
-		en:         h.en,
-		groups:     gs,
+		opts:        h.opts,
+		mu:          h.mu,
+		w:           h.w,
+		attrPrefix:  h.attrPrefix,
+		detailAttrs: h.detailAttrs,
+		prettyAttrs: h.prettyAttrs,
+		en:          h.en,
+		groups:      gs,
 	}
 }
 
@@ -87,34 +265,124 @@ func (h *textHandler) Handle(ctx context.Context, r slog.Record) error {
 	// Write Emoji Level
 	h.en.writeEmojiLevel(buf, r.Level)
 
-	// Write Time
-	h.en.writeTime(buf, r.Time)
+	// The level, source and message are padded to their columns in
+	// LayoutColumns, from the marks taken before each is written.
+	var mark, msgMark int
+	var wrapped string
+	if rep := h.opts.ReplaceAttr; rep == nil {
+		// Write Time
+		h.en.writeTime(buf, r.Time)
 
-	// Write Level
-	h.en.writeLevel(buf, r.Level)
+		// Write Level
+		mark = len(*buf)
+		h.en.writeLevel(buf, r.Level)
+		h.en.fitColumn(buf, mark, columnLevel)
+
+		// Write Source
+		mark = len(*buf)
+		h.en.writeSource(buf, sourceAttr(h.opts, r.PC))
+		h.en.fitColumn(buf, mark, columnSource)
+
+		// Write Message
+		msgMark = len(*buf)
+		h.en.writeMessage(buf, r.Message)
+		wrapped = h.en.fitColumn(buf, msgMark, columnMessage)
+	} else {
+		// Write built-in attributes as rewritten by ReplaceAttr
+		if !r.Time.IsZero() {
+			h.en.writeTimeAttr(buf, rep(nil, slog.Time(slog.TimeKey, r.Time)))
+		}
+		mark = len(*buf)
+		h.en.writeLevelAttr(buf, rep(nil, slog.Any(slog.LevelKey, r.Level)))
+		h.en.fitColumn(buf, mark, columnLevel)
+		mark = len(*buf)
+		h.en.writeSource(buf, sourceAttr(h.opts, r.PC))
+		h.en.fitColumn(buf, mark, columnSource)
+		msgMark = len(*buf)
+		h.en.writeMessageAttr(buf, rep(nil, slog.String(slog.MessageKey, r.Message)))
+		wrapped = h.en.fitColumn(buf, msgMark, columnMessage)
+	}
 
-	// Write Message
-	h.en.writeMessage(buf, r.Message)
+	// Write Trace
+	h.en.writeTrace(buf, traceAttrs(h.opts, ctx))
 
 	// Wrote attrPrefix
+	attrsMark := len(*buf)
 	if prefix := h.attrPrefix; len(prefix) > 0 {
 		buf.Write(h.attrPrefix)
 	}
 
 	// Write Attributes
-	if r.NumAttrs() > 0 {
-		r.Attrs(func(a slog.Attr) bool {
-			if rep := h.opts.ReplaceAttr; rep != nil {
-				a = rep(h.groups, a)
-			}
+	detailAttrs := slices.Clip(h.detailAttrs)
+	var tree []*treeNode // the attributes as written, for PrettyMode
+	if h.opts.Pretty != PrettyOff {
+		for _, ga := range h.prettyAttrs {
+			tree = h.en.addTreeAttr(tree, ga.groups, ga.attr)
+		}
+	}
+	dropped := 0 // attributes cut by MaxRecordBytes
+	writeAttr := func(a slog.Attr) bool {
+		// Once one attribute is dropped the rest are too, so that the
+		// record does not skip around.
+		if dropped > 0 {
+			dropped++
+			return true
+		}
 
-			h.en.writeAttr(buf, h.groups, a)
+		mark := len(*buf)
+		a = replaceAttr(h.opts.ReplaceAttr, h.groups, a)
+		h.en.writeAttr(buf, h.groups, a)
+		if exceedsRecordBytes(h.opts, buf, mark) {
+			dropped++
 			return true
-		})
+		}
+		detailAttrs = h.en.appendDetailAttrs(detailAttrs, h.groups, a)
+		if h.opts.Pretty != PrettyOff {
+			tree = h.en.addTreeAttr(tree, h.groups, a)
+		}
+		return true
+	}
+	if r.NumAttrs() > 0 {
+		r.Attrs(writeAttr)
+	}
+
+	// Write Context Attributes
+	for _, a := range contextAttrs(ctx, h.opts.ContextExtractors) {
+		writeAttr(a)
+	}
+
+	if dropped > 0 {
+		a := slog.Int(TruncatedKey, dropped)
+		h.en.writeAttr(buf, h.groups, a)
+		if h.opts.Pretty != PrettyOff {
+			tree = h.en.addTreeAttr(tree, h.groups, a)
+		}
 	}
 
+	// Move the attributes below the line as a tree in PrettyMode
+	pretty := h.en.usePretty(buf)
+	if pretty {
+		*buf = (*buf)[:attrsMark]
+	}
+
+	// Fit the line to the terminal
+	h.en.fitLine(buf, attrsMark)
+
 	h.en.writeNewline(buf)
 
+	// Write the rest of a wrapped message below the message column
+	if wrapped != "" {
+		h.en.writeWrapped(buf, wrapped, stringWidth(string((*buf)[:msgMark])))
+	}
+	if pretty {
+		h.en.writeTree(buf, tree, "")
+	}
+
+	// Write Error Details and Hex Dumps
+	for _, a := range detailAttrs {
+		h.en.writeDetails(buf, a)
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	_, err := h.w.Write(*buf)
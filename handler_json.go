@@ -0,0 +1,177 @@
+package slogja
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+type jsonHandler struct {
+	opts        HandlerOptions
+	attrPrefix  []byte
+	groups      []string
+	nOpenGroups int
+
+	mu *sync.Mutex // shared by all handlers derived from the same root
+	w  io.Writer
+	en *encodeJSON
+}
+
+// NewJSONHandler creates a handler that writes each record as a single line
+// JSON object. It accepts the same HandlerOptions as NewTextHandler; options
+// that only affect terminal output, such as colors and emoji, are ignored.
+func NewJSONHandler(w io.Writer, opts *HandlerOptions) *jsonHandler {
+	if opts == nil {
+		opts = &HandlerOptions{
+			Level: slog.LevelInfo,
+		}
+	}
+
+	return &jsonHandler{
+		opts:   *opts,
+		mu:     &sync.Mutex{},
+		w:      w,
+		groups: make([]string, 0, 5),
+		en:     newEncodeJSON(*opts),
+	}
+}
+
+func (h *jsonHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= minLevel(h.opts.Level)
+}
+
+func (h *jsonHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	buf := buffer(slices.Clone(h.attrPrefix))
+
+	// Groups are opened lazily so that a group without attributes is not
+	// written at all.
+	for _, g := range h.groups[h.nOpenGroups:] {
+		h.en.writeOpenGroup(&buf, g)
+	}
+	for _, a := range attrs {
+		h.en.writeAttr(&buf, replaceAttr(h.opts.ReplaceAttr, h.groups, a))
+	}
+
+	return &jsonHandler{
+		opts:        h.opts,
+		mu:          h.mu,
+		w:           h.w,
+		groups:      h.groups,
+		nOpenGroups: len(h.groups),
+		en:          h.en,
+		attrPrefix:  buf,
+	}
+}
+
+func (h *jsonHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	gs := make([]string, len(h.groups)+1)
+	if len(h.groups) > 0 {
+		copy(gs, h.groups)
+	}
+	gs[len(gs)-1] = name
+
+	return &jsonHandler{
+		opts:        h.opts,
+		mu:          h.mu,
+		w:           h.w,
+		attrPrefix:  h.attrPrefix,
+		nOpenGroups: h.nOpenGroups,
+		en:          h.en,
+		groups:      gs,
+	}
+}
+
+func (h *jsonHandler) Handle(ctx context.Context, r slog.Record) error {
+	buf := newBuffer()
+	defer buf.Free()
+
+	buf.WriteByte('{')
+
+	if rep := h.opts.ReplaceAttr; rep == nil {
+		// Write Time
+		h.en.writeTime(buf, r.Time)
+
+		// Write Level
+		h.en.writeLevel(buf, r.Level)
+
+		// Write Source
+		h.en.writeSource(buf, sourceAttr(h.opts, r.PC))
+
+		// Write Message
+		h.en.writeMessage(buf, r.Message)
+	} else {
+		// Write built-in attributes as rewritten by ReplaceAttr
+		if !r.Time.IsZero() {
+			h.en.writeTimeAttr(buf, rep(nil, slog.Time(slog.TimeKey, r.Time)))
+		}
+		h.en.writeLevelAttr(buf, rep(nil, slog.Any(slog.LevelKey, r.Level)))
+		h.en.writeSource(buf, sourceAttr(h.opts, r.PC))
+		h.en.writeMessageAttr(buf, rep(nil, slog.String(slog.MessageKey, r.Message)))
+	}
+
+	// Write Trace
+	for _, a := range traceAttrs(h.opts, ctx) {
+		h.en.writeAttr(buf, a)
+	}
+
+	// Wrote attrPrefix
+	if prefix := h.attrPrefix; len(prefix) > 0 {
+		buf.Write(h.attrPrefix)
+	}
+
+	// Write Attributes
+	nOpen := h.nOpenGroups
+	ctxAttrs := contextAttrs(ctx, h.opts.ContextExtractors)
+	if r.NumAttrs() > 0 || len(ctxAttrs) > 0 {
+		for _, g := range h.groups[h.nOpenGroups:] {
+			h.en.writeOpenGroup(buf, g)
+		}
+		nOpen = len(h.groups)
+
+		dropped := 0 // attributes cut by MaxRecordBytes
+		writeAttr := func(a slog.Attr) bool {
+			if dropped > 0 {
+				dropped++
+				return true
+			}
+
+			mark := len(*buf)
+			h.en.writeAttr(buf, replaceAttr(h.opts.ReplaceAttr, h.groups, a))
+			if exceedsRecordBytes(h.opts, buf, mark) {
+				dropped++
+			}
+			return true
+		}
+		r.Attrs(writeAttr)
+
+		// Write Context Attributes
+		for _, a := range ctxAttrs {
+			writeAttr(a)
+		}
+
+		if dropped > 0 {
+			h.en.writeAttr(buf, slog.Int(TruncatedKey, dropped))
+		}
+	}
+
+	for range nOpen {
+		h.en.writeCloseGroup(buf)
+	}
+	h.en.writeCloseObject(buf)
+	h.en.writeNewline(buf)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(*buf)
+	return err
+}
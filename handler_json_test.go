@@ -0,0 +1,146 @@
+package slogja
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestJSONEnabled(t *testing.T) {
+	h := NewJSONHandler(nil, &HandlerOptions{
+		Level: slog.LevelWarn,
+	})
+
+	if !h.Enabled(context.Background(), slog.LevelError) {
+		t.Error("Expected Enabled to return true for LevelError")
+	}
+
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected Enabled to return false for LevelInfo")
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	rec := slog.NewRecord(
+		time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+		slog.LevelInfo,
+		"test \"message\"",
+		uintptr(0),
+	)
+	rec.AddAttrs(slog.String("key1", "value1"), slog.Any("list", []int{1, 2}))
+
+	t.Run("should write record as json object", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		h := NewJSONHandler(b, &HandlerOptions{
+			Level:      slog.LevelInfo,
+			TimeFormat: time.RFC3339,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == "key1" {
+					return slog.String("key1", "replaced-value")
+				}
+				return a
+			},
+		})
+
+		if err := h.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := `{"time":"2023-10-01T12:00:00Z","level":"INFO","msg":"test \"message\"","key1":"replaced-value","list":[1,2]}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should escape time formatted with a custom layout", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		h := NewJSONHandler(b, &HandlerOptions{TimeFormat: `"2006"\01`})
+
+		if err := h.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !json.Valid(b.Bytes()) || !bytes.HasPrefix(b.Bytes(), []byte(`{"time":"\"2023\"\\10",`)) {
+			t.Errorf("Expected valid json with escaped time, got '%s'", b.String())
+		}
+	})
+
+	t.Run("should nest attributes under groups", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		var h slog.Handler = NewJSONHandler(b, &HandlerOptions{DisableTime: true})
+		h = h.WithAttrs([]slog.Attr{slog.String("pre", "attr")})
+		h = h.WithGroup("g1").WithAttrs([]slog.Attr{slog.Int("n", 1)})
+		h = h.WithGroup("g2")
+
+		if err := h.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := `{"level":"INFO","msg":"test \"message\"","pre":"attr","g1":{"n":1,"g2":{"key1":"value1","list":[1,2]}}}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should omit group without attributes", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		h := NewJSONHandler(b, &HandlerOptions{DisableTime: true}).WithGroup("g")
+
+		empty := slog.NewRecord(time.Time{}, slog.LevelWarn, "empty", 0)
+		if err := h.Handle(context.Background(), empty); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := `{"level":"WARN","msg":"empty"}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should write valid json", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, nil))
+		l.With("a", 1).WithGroup("g").Info("hello\n\x1b[31m",
+			slog.Group("users", slog.String("type", "json"), slog.Any("data", map[string]any{"k": []string{"v"}})),
+			slog.Any("nil", nil),
+		)
+
+		var out map[string]any
+		if err := json.Unmarshal(b.Bytes(), &out); err != nil {
+			t.Fatalf("Expected valid json, got %v: %s", err, b.String())
+		}
+		if out["msg"] != "hello\n\x1b[31m" {
+			t.Errorf("Expected msg to round trip, got %q", out["msg"])
+		}
+	})
+}
+
+func TestJSONHandlerAddSource(t *testing.T) {
+	b := bytes.NewBuffer([]byte{})
+	h := NewJSONHandler(b, &HandlerOptions{AddSource: true})
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", callerPC())
+	if err := h.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var out struct {
+		Source struct {
+			Function string `json:"function"`
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &out); err != nil {
+		t.Fatalf("Expected valid json, got %v: %s", err, b.String())
+	}
+	if out.Source.Function != "github.com/kongsakchai/slogja.TestJSONHandlerAddSource" {
+		t.Errorf("Expected source function, got '%s'", out.Source.Function)
+	}
+	if out.Source.Line == 0 {
+		t.Error("Expected source line to be set")
+	}
+}
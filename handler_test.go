@@ -3,7 +3,15 @@ package slogja
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -101,3 +109,349 @@ func TestHandler(t *testing.T) {
 	// 	t.Error("Expected buffer to contain the log message")
 	// }
 }
+
+func TestHandlerAddSource(t *testing.T) {
+	t.Run("should write source location before message", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		h := NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			DisableTime:  true,
+			AddSource:    true,
+		})
+
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", callerPC())
+		if err := h.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !bytes.HasPrefix(b.Bytes(), []byte("INF ")) || !bytes.Contains(b.Bytes(), []byte("/handler_test.go:")) {
+			t.Errorf("Expected output to start with source location, got '%s'", b.String())
+		}
+	})
+
+	t.Run("should drop source when ReplaceAttr returns empty attr", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		h := NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			DisableTime:  true,
+			AddSource:    true,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.SourceKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})
+
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", callerPC())
+		if err := h.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := "INF \"msg\" \n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+}
+
+// callerPC returns the program counter of its caller, as slog.Logger does.
+func callerPC() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	return pcs[0]
+}
+
+func TestHandlerHostileInput(t *testing.T) {
+	b := bytes.NewBuffer([]byte{})
+	l := slog.New(NewTextHandler(b, &HandlerOptions{
+		DisableColor: true,
+		DisableEmoji: true,
+		DisableTime:  true,
+		Quote:        QuoteAuto,
+	}))
+
+	l.WithGroup("g\nINF").Info("login\nINF \"admin\" logged in",
+		slog.String("user", "bob\x1b[2K\rroot"),
+		slog.String("a=b", "ok"),
+	)
+
+	expected := `INF "login\nINF \"admin\" logged in" "g\nINF.user"="bob\x1b[2K\rroot" "g\nINF.a=b"=ok ` + "\n"
+	if b.String() != expected {
+		t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+	}
+	if bytes.Count(b.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("Expected a single line, got '%s'", b.String())
+	}
+
+	b.Reset()
+	l.Info("stringer",
+		slog.Any("st", Data{Value: `x" admin=true`}),
+		slog.Any("list", []Data{{Value: "a b=c"}}),
+		slog.Any("raw", json.RawMessage(`{"a": "x y"}`)),
+		slog.Any("c", complex(1, 2)),
+	)
+
+	expected = `INF stringer st="x\" admin=true" list=["a b=c"] raw="{\"a\":\"x y\"}" c=(1+2i) ` + "\n"
+	if b.String() != expected {
+		t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+	}
+
+	b.Reset()
+	l.Info("keys", slog.Any("v", []slog.Value{slog.GroupValue(slog.String("evil\n\x1b[31mERR fake", "x"))}))
+
+	expected = `INF keys v=[{"evil\n\x1b[31mERR fake":x}] ` + "\n"
+	if b.String() != expected {
+		t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+	}
+}
+
+func TestHandlerErrorDetails(t *testing.T) {
+	b := bytes.NewBuffer([]byte{})
+	l := slog.New(NewTextHandler(b, &HandlerOptions{
+		DisableColor: true,
+		DisableEmoji: true,
+		DisableTime:  true,
+		ErrorChain:   true,
+	}))
+
+	err := fmt.Errorf("query: %w", errors.New("conn refused"))
+	l.With("cause", err).Error("failed", "err", err, "status", 500)
+
+	expected := `ERR "failed" cause="query: conn refused" err="query: conn refused" status=500 ` + "\n" +
+		"  cause:\n" +
+		"    ↳ conn refused\n" +
+		"  err:\n" +
+		"    ↳ conn refused\n"
+	if b.String() != expected {
+		t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+	}
+}
+
+func TestEnabledLevelVar(t *testing.T) {
+	lv := new(slog.LevelVar)
+	lv.Set(slog.LevelWarn)
+
+	root := NewTextHandler(nil, &HandlerOptions{Level: lv})
+	derived := []slog.Handler{
+		root,
+		root.WithAttrs([]slog.Attr{slog.String("key", "value")}),
+		root.WithGroup("group").WithAttrs([]slog.Attr{slog.Int("n", 1)}),
+		NewJSONHandler(nil, &HandlerOptions{Level: lv}).WithGroup("group"),
+	}
+
+	for _, h := range derived {
+		if h.Enabled(context.Background(), slog.LevelInfo) {
+			t.Error("Expected Enabled to return false for LevelInfo")
+		}
+	}
+
+	lv.Set(slog.LevelDebug)
+	for _, h := range derived {
+		if !h.Enabled(context.Background(), slog.LevelDebug) {
+			t.Error("Expected Enabled to return true for LevelDebug after LevelVar changed")
+		}
+	}
+}
+
+func TestEnabledDefaultLevel(t *testing.T) {
+	h := NewTextHandler(nil, &HandlerOptions{})
+
+	if !h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected Enabled to return true for LevelInfo")
+	}
+
+	if h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Expected Enabled to return false for LevelDebug")
+	}
+}
+
+// unsafeWriter is not safe for concurrent use. It writes byte by byte so
+// that unsynchronised callers interleave and the race detector notices.
+type unsafeWriter struct {
+	b []byte
+}
+
+func (w *unsafeWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		w.b = append(w.b, c)
+	}
+	return len(p), nil
+}
+
+func TestHandlerSharedMutex(t *testing.T) {
+	const goroutines = 16
+	const iterations = 200
+
+	for _, tt := range []struct {
+		name string
+		new  func(w io.Writer) slog.Handler
+	}{
+		{name: "text", new: func(w io.Writer) slog.Handler {
+			return NewTextHandler(w, &HandlerOptions{DisableColor: true, DisableEmoji: true, DisableTime: true})
+		}},
+		{name: "json", new: func(w io.Writer) slog.Handler {
+			return NewJSONHandler(w, &HandlerOptions{DisableTime: true})
+		}},
+	} {
+		t.Run("should not interleave writes of derived "+tt.name+" handlers", func(t *testing.T) {
+			w := &unsafeWriter{}
+			root := tt.new(w)
+
+			var wg sync.WaitGroup
+			for i := range goroutines {
+				var h slog.Handler
+				switch i % 3 {
+				case 0:
+					h = root
+				case 1:
+					h = root.WithAttrs([]slog.Attr{slog.Int("worker", i)})
+				default:
+					h = root.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("worker", i)})
+				}
+
+				wg.Add(1)
+				go func(l *slog.Logger) {
+					defer wg.Done()
+					for j := range iterations {
+						l.Info("message", "iteration", j)
+					}
+				}(slog.New(h))
+			}
+			wg.Wait()
+
+			lines := strings.Split(strings.TrimSuffix(string(w.b), "\n"), "\n")
+			if len(lines) != goroutines*iterations {
+				t.Fatalf("Expected %d lines, got %d", goroutines*iterations, len(lines))
+			}
+			for _, line := range lines {
+				if strings.Count(line, "message") != 1 || strings.Count(line, "iteration") != 1 {
+					t.Fatalf("Expected an intact line, got '%s'", line)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerReplaceAttr(t *testing.T) {
+	type call struct {
+		groups string
+		key    string
+	}
+
+	redact := func(calls *[]call) replaceAttrFunc {
+		return func(groups []string, a slog.Attr) slog.Attr {
+			*calls = append(*calls, call{groups: strings.Join(groups, "."), key: a.Key})
+			switch a.Key {
+			case "password":
+				return slog.String(a.Key, "***")
+			case "drop":
+				return slog.Attr{}
+			case slog.TimeKey:
+				return slog.Attr{}
+			case slog.LevelKey:
+				return slog.String(a.Key, "NOTICE")
+			case slog.MessageKey:
+				return slog.String(a.Key, "rewritten")
+			}
+			return a
+		}
+	}
+
+	newRecord := func() slog.Record {
+		rec := slog.NewRecord(time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC), slog.LevelInfo, "msg", 0)
+		rec.AddAttrs(
+			slog.Group("user", slog.String("name", "bob"), slog.String("password", "secret"), slog.Int("drop", 1)),
+			slog.String("drop", "x"),
+		)
+		return rec
+	}
+
+	t.Run("should replace attributes everywhere in text handler", func(t *testing.T) {
+		var calls []call
+		b := bytes.NewBuffer([]byte{})
+		var h slog.Handler = NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			ReplaceAttr:  redact(&calls),
+		})
+		h = h.WithAttrs([]slog.Attr{slog.String("password", "pre")}).WithGroup("req")
+
+		if err := h.Handle(context.Background(), newRecord()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := `"NOTICE" "rewritten" password="***" req.user.name="bob" req.user.password="***" ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+
+		expectedCalls := []call{
+			{groups: "", key: "password"},
+			{groups: "", key: slog.TimeKey},
+			{groups: "", key: slog.LevelKey},
+			{groups: "", key: slog.MessageKey},
+			{groups: "req.user", key: "name"},
+			{groups: "req.user", key: "password"},
+			{groups: "req.user", key: "drop"},
+			{groups: "req", key: "drop"},
+		}
+		if !reflect.DeepEqual(calls, expectedCalls) {
+			t.Errorf("Expected calls '%v', got '%v'", expectedCalls, calls)
+		}
+	})
+
+	t.Run("should replace attributes everywhere in json handler", func(t *testing.T) {
+		var calls []call
+		b := bytes.NewBuffer([]byte{})
+		var h slog.Handler = NewJSONHandler(b, &HandlerOptions{ReplaceAttr: redact(&calls)})
+		h = h.WithAttrs([]slog.Attr{slog.String("password", "pre")}).WithGroup("req")
+
+		if err := h.Handle(context.Background(), newRecord()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := `{"level":"NOTICE","msg":"rewritten","password":"***","req":{"user":{"name":"bob","password":"***"}}}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should keep level style when level is replaced with a level", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		h := NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			DisableTime:  true,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.LevelKey {
+					return slog.Any(a.Key, slog.LevelError)
+				}
+				return a
+			},
+		})
+
+		if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := `ERR "msg" ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+}
+
+func TestWithAttrsAccumulates(t *testing.T) {
+	b := bytes.NewBuffer([]byte{})
+	l := slog.New(NewTextHandler(b, &HandlerOptions{DisableColor: true, DisableEmoji: true, DisableTime: true}))
+
+	l.With("a", 1).With("b", 2).Info("msg")
+
+	expected := `INF "msg" a=1 b=2 ` + "\n"
+	if b.String() != expected {
+		t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+	}
+}
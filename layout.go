@@ -0,0 +1,234 @@
+package slogja
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// LayoutMode selects how the text handler lays out the start of a line.
+type LayoutMode int
+
+const (
+	// LayoutPlain writes the level, source and message followed by a single
+	// space each.
+	LayoutPlain LayoutMode = iota
+	// LayoutColumns pads the level, source and message to fixed widths, so
+	// that the attributes of consecutive lines start at the same column
+	// and read like a table.
+	LayoutColumns
+)
+
+// OverflowMode selects what LayoutColumns does with a message wider than
+// its column.
+type OverflowMode int
+
+const (
+	// OverflowTruncate cuts the message and ends it with "…".
+	OverflowTruncate OverflowMode = iota
+	// OverflowWrap goes on with the message on the following lines,
+	// aligned with the message column.
+	OverflowWrap
+)
+
+const (
+	defaultMessageWidth = 40
+	defaultSourceWidth  = 24
+)
+
+// column names a padded part of the line in LayoutColumns.
+type column int
+
+const (
+	columnLevel column = iota
+	columnSource
+	columnMessage
+)
+
+// columnWidth returns the width of c in cells, without the space after it.
+func (e *encodeText) columnWidth(c column) int {
+	switch c {
+	case columnLevel:
+		if e.opt.LevelWidth > 0 {
+			return e.opt.LevelWidth
+		}
+		w := 0
+		for _, style := range e.levels {
+			w = max(w, stringWidth(style.Label))
+		}
+		return w
+	case columnSource:
+		if e.opt.SourceWidth > 0 {
+			return e.opt.SourceWidth
+		}
+		return defaultSourceWidth
+	default:
+		if e.opt.MessageWidth > 0 {
+			return e.opt.MessageWidth
+		}
+		return defaultMessageWidth
+	}
+}
+
+// fitColumn pads what was written to buf since mark to the width of c, plus
+// the separating space, when the layout is LayoutColumns. A wider level is
+// left as is, so that labels with an offset such as "WRN+2" stay whole.
+// Other wider text is cut to fit: a source keeps its end, where the line
+// number is, and a message its start. The text of a message left over for
+// OverflowWrap is returned for writeWrapped.
+func (e *encodeText) fitColumn(buf *buffer, mark int, c column) (rest string) {
+	if e.opt.Layout != LayoutColumns || len(*buf) == mark {
+		return ""
+	}
+
+	width := e.columnWidth(c)
+	seg := string((*buf)[mark:])
+	plain := strings.TrimRight(stripANSI(seg), " ")
+
+	if c != columnLevel && stringWidth(plain) > width {
+		from, to, ellipsis := 0, len(plain), "…"
+		switch {
+		case c == columnSource:
+			from = cutWidthLeft(plain, width-1)
+			ellipsis = ""
+		case e.opt.MessageOverflow == OverflowWrap && c == columnMessage:
+			to = wrapIndex(plain, width)
+			rest = strings.TrimLeft(plain[to:], " ")
+			ellipsis = ""
+		default:
+			to = cutWidth(plain, width-1)
+		}
+		*buf = appendVisible((*buf)[:mark], seg, from, to, ellipsis)
+	}
+
+	for n := width + 1 - stringWidth(string((*buf)[mark:])); n > 0; n-- {
+		e.writeSpace(buf)
+	}
+	return rest
+}
+
+// fitMessage writes the message s with write. In LayoutColumns with
+// OverflowTruncate, a message wider than its column is written again cut
+// and ended with "…" until it fits. The cut is made before write quotes and
+// escapes s, so the quotes stay balanced and no escape is split.
+func (e *encodeText) fitMessage(buf *buffer, s string, write func(buf *buffer, s string)) {
+	mark := len(*buf)
+	write(buf, s)
+	if e.opt.Layout != LayoutColumns || e.opt.MessageOverflow != OverflowTruncate {
+		return
+	}
+
+	width := e.columnWidth(columnMessage)
+	fits := func() bool {
+		return stringWidth(strings.TrimRight(string((*buf)[mark:]), " ")) <= width
+	}
+	// Quoting and escaping only widen s, so the cut starts from the longest
+	// prefix that fits as is and drops one rune at a time from there.
+	for n := cutWidth(s, width); n > 0 && !fits(); {
+		_, size := utf8.DecodeLastRuneInString(s[:n])
+		n -= size
+
+		*buf = (*buf)[:mark]
+		write(buf, s[:n]+"…")
+	}
+}
+
+// writeWrapped writes the rest of a message cut by fitColumn, one line per
+// column width, indented by indent cells.
+func (e *encodeText) writeWrapped(buf *buffer, rest string, indent int) {
+	width := e.columnWidth(columnMessage)
+	for rest != "" {
+		i := wrapIndex(rest, width)
+
+		buf.WriteString(strings.Repeat(" ", indent))
+		e.style(buf, e.theme.Message)
+		buf.WriteString(strings.TrimRight(rest[:i], " "))
+		e.endStyle(buf, e.theme.Message)
+		e.writeNewline(buf)
+
+		rest = strings.TrimLeft(rest[i:], " ")
+	}
+}
+
+// appendVisible appends seg to b with only the plain text between the byte
+// offsets from and to kept. "…" marks a cut at the start and ellipsis one at
+// the end. Escape sequences up to the end of the kept text are kept and a
+// reset replaces those after it.
+func appendVisible(b []byte, seg string, from, to int, ellipsis string) []byte {
+	p := 0 // offset in the plain text
+	styled := false
+	for i := 0; i < len(seg); {
+		if n := ansiLen(seg[i:]); n > 0 {
+			if p < to {
+				b = append(b, seg[i:i+n]...)
+			}
+			styled = true
+			i += n
+			continue
+		}
+
+		switch {
+		case p == from && from > 0:
+			b = append(b, "…"...)
+		case p == to:
+			b = append(b, ellipsis...)
+		}
+		if p >= from && p < to {
+			b = append(b, seg[i])
+		}
+		i++
+		p++
+	}
+	if styled && to < p {
+		b = append(b, txtReset...)
+	}
+	return b
+}
+
+// stripANSI returns s without its escape sequences.
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b[") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := ansiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// cutWidthLeft returns the offset of the longest suffix of s that fits in
+// width cells.
+func cutWidthLeft(s string, width int) int {
+	w := stringWidth(s)
+	i := 0
+	for w > width && i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w -= runeWidth(r)
+		i += size
+	}
+	return i
+}
+
+// wrapIndex returns where to break s so that the first part fits in width
+// cells, after the last space if there is one, and after at least one rune
+// so that wrapping always makes progress.
+func wrapIndex(s string, width int) int {
+	i := cutWidth(s, width)
+	if i == len(s) {
+		return i
+	}
+	if i == 0 {
+		_, size := utf8.DecodeRuneInString(s)
+		return size
+	}
+	if sp := strings.LastIndexByte(s[:i+1], ' '); sp > 0 {
+		return sp
+	}
+	return i
+}
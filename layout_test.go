@@ -0,0 +1,180 @@
+package slogja
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerLayoutColumns(t *testing.T) {
+	newHandler := func(b *bytes.Buffer, opt HandlerOptions) *textHandler {
+		opt.DisableEmoji = true
+		opt.DisableTime = true
+		opt.Layout = LayoutColumns
+		return NewTextHandler(b, &opt)
+	}
+
+	tests := []struct {
+		name     string
+		opt      HandlerOptions
+		records  []slog.Record
+		expected string
+	}{
+		{
+			name: "should start attributes at the same column",
+			opt:  HandlerOptions{DisableColor: true, Level: slog.LevelDebug, MessageWidth: 12},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelInfo, "started", "port", 8080),
+				newLayoutRecord(slog.LevelWarn, "slow", "took", "2s"),
+			},
+			expected: `INF "started"    port=8080 ` + "\n" +
+				`WRN "slow"       took="2s" ` + "\n",
+		},
+		{
+			name: "should truncate long message",
+			opt:  HandlerOptions{DisableColor: true, MessageWidth: 12},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelInfo, "a message that is too long", "k", 1),
+			},
+			expected: `INF "a message…" k=1 ` + "\n",
+		},
+		{
+			name: "should wrap long message below its column",
+			opt:  HandlerOptions{DisableColor: true, MessageWidth: 12, MessageOverflow: OverflowWrap},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelInfo, "a message that is too long", "k", 1),
+			},
+			expected: `INF "a message   k=1 ` + "\n" +
+				`    that is too` + "\n" +
+				`    long"` + "\n",
+		},
+		{
+			name: "should measure wide characters",
+			opt:  HandlerOptions{DisableColor: true, Quote: QuoteAuto, MessageWidth: 8},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelInfo, "日本", "k", 1),
+				newLayoutRecord(slog.LevelInfo, "日本語の文", "k", 2),
+			},
+			expected: "INF 日本     k=1 \n" +
+				"INF 日本語…  k=2 \n",
+		},
+		{
+			name: "should pad custom level labels",
+			opt: HandlerOptions{DisableColor: true, MessageWidth: 4, Levels: []LevelStyle{
+				{Level: slog.LevelInfo, Label: "INFO"},
+				{Level: slog.LevelError, Label: "E"},
+			}},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelError, "x", "k", 1),
+			},
+			expected: `E    "x"  k=1 ` + "\n",
+		},
+		{
+			name: "should cut message before escaping it",
+			opt:  HandlerOptions{DisableColor: true, MessageWidth: 10},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelInfo, "ab\x1b[31mcdef", "k", 1),
+			},
+			expected: `INF "ab\x1b[…" k=1 ` + "\n",
+		},
+		{
+			name: "should not cut level labels with an offset",
+			opt:  HandlerOptions{DisableColor: true, Level: slog.LevelDebug - 4, MessageWidth: 3},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelWarn+2, "x", "k", 1),
+				newLayoutRecord(slog.LevelDebug-4, "y", "k", 2),
+				newLayoutRecord(slog.LevelInfo, "z", "k", 3),
+			},
+			expected: `WRN+2 "x" k=1 ` + "\n" +
+				`DBG-4 "y" k=2 ` + "\n" +
+				`INF "z" k=3 ` + "\n",
+		},
+		{
+			name: "should keep colors when cutting",
+			opt:  HandlerOptions{Color: ColorAlways, Theme: &Theme{Message: txtBold}, MessageWidth: 4},
+			records: []slog.Record{
+				newLayoutRecord(slog.LevelInfo, "hello", "k", 1),
+			},
+			expected: `INF ` + txtBold + `"h…"` + txtReset + ` k=1 ` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			h := newHandler(b, tt.opt)
+			for _, r := range tt.records {
+				if err := h.Handle(context.Background(), r); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if b.String() != tt.expected {
+				t.Errorf("Expected:\n%q\ngot:\n%q", tt.expected, b.String())
+			}
+		})
+	}
+
+	t.Run("should keep the end of a long source", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := newHandler(b, HandlerOptions{DisableColor: true, AddSource: true, SourceWidth: 10, MessageWidth: 3})
+
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "m", callerPC())
+		if err := h.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		line := b.String()
+		src, _, _ := strings.Cut(strings.TrimPrefix(line, "INF "), " ")
+		if !strings.HasPrefix(src, "…") || stringWidth(src) != 10 || !strings.Contains(src, ":") {
+			t.Errorf("Expected source cut to 10 cells keeping the line number, got %q", line)
+		}
+		if !strings.HasSuffix(line, ` "m" `+"\n") {
+			t.Errorf("Expected message after the source column, got %q", line)
+		}
+	})
+
+	t.Run("should not pad in plain layout", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		h := NewTextHandler(b, &HandlerOptions{DisableColor: true, DisableEmoji: true, DisableTime: true})
+		if err := h.Handle(context.Background(), newLayoutRecord(slog.LevelInfo, "x", "k", 1)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := `INF "x" k=1 ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, b.String())
+		}
+	})
+}
+
+func newLayoutRecord(level slog.Level, msg string, args ...any) slog.Record {
+	r := slog.NewRecord(time.Time{}, level, msg, 0)
+	r.Add(args...)
+	return r
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected int
+	}{
+		{name: "should count ASCII", s: "hello", expected: 5},
+		{name: "should skip escape sequences", s: "\x1b[1;31mhi\x1b[0m", expected: 2},
+		{name: "should count wide characters twice", s: "日本語", expected: 6},
+		{name: "should count emoji twice", s: "🌱", expected: 2},
+		{name: "should skip combining marks", s: "é", expected: 1},
+		{name: "should count fullwidth forms twice", s: "ＡＢ", expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stringWidth(tt.s); got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
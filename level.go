@@ -0,0 +1,74 @@
+package slogja
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+)
+
+// LevelStyle describes how a level is rendered. An entry applies to its
+// Level and to every level above it up to the next entry; levels that fall
+// between entries get the label of the entry below with the offset
+// appended, e.g. "WRN+2".
+type LevelStyle struct {
+	Level slog.Level
+	// Label is written in place of the level, e.g. "TRC".
+	Label string
+	// Color is the ANSI escape sequence for the label. If empty, the
+	// theme's color for the standard level at or below Level is used.
+	Color string
+	// Emoji is written before the time, followed by a space.
+	Emoji string
+}
+
+// defaultLevels is used when HandlerOptions.Levels is empty. The warning
+// sign carries an extra space because terminals render it one cell narrower
+// than the other emoji.
+var defaultLevels = levelTable{
+	{Level: slog.LevelDebug, Label: "DBG", Emoji: "🐛"},
+	{Level: slog.LevelInfo, Label: "INF", Emoji: "🌱"},
+	{Level: slog.LevelWarn, Label: "WRN", Emoji: "⚠️ "},
+	{Level: slog.LevelError, Label: "ERR", Emoji: "❌"},
+}
+
+// levelTable holds level styles sorted by level.
+type levelTable []LevelStyle
+
+func newLevelTable(styles []LevelStyle) levelTable {
+	if len(styles) == 0 {
+		return defaultLevels
+	}
+
+	t := levelTable(slices.Clone(styles))
+	slices.SortStableFunc(t, func(a, b LevelStyle) int {
+		return int(a.Level) - int(b.Level)
+	})
+	return t
+}
+
+// lookup returns the style covering level and the offset of level from the
+// style's level. Levels below the first entry use the first entry with a
+// negative offset.
+func (t levelTable) lookup(level slog.Level) (LevelStyle, int) {
+	i := 0
+	for j := range t {
+		if t[j].Level > level {
+			break
+		}
+		i = j
+	}
+	return t[i], int(level - t[i].Level)
+}
+
+// appendLevelLabel appends the label of style followed by a signed offset
+// when the level is not an exact match.
+func appendLevelLabel(b []byte, style LevelStyle, offset int) []byte {
+	b = append(b, style.Label...)
+	if offset > 0 {
+		b = append(b, '+')
+	}
+	if offset != 0 {
+		b = strconv.AppendInt(b, int64(offset), 10)
+	}
+	return b
+}
@@ -0,0 +1,139 @@
+package slogja
+
+import (
+	"log/slog"
+	"testing"
+)
+
+const (
+	levelTrace  = slog.Level(-8)
+	levelNotice = slog.Level(2)
+	levelFatal  = slog.Level(12)
+)
+
+var customLevels = []LevelStyle{
+	{Level: levelFatal, Label: "FTL", Color: txtMagenta, Emoji: "💀"},
+	{Level: levelTrace, Label: "TRC", Color: txtGray},
+	{Level: slog.LevelDebug, Label: "DBG"},
+	{Level: slog.LevelInfo, Label: "INF", Color: txtGreen},
+	{Level: levelNotice, Label: "NTC", Color: txtCyan},
+	{Level: slog.LevelWarn, Label: "WRN", Color: txtYellow},
+	{Level: slog.LevelError, Label: "ERR", Color: txtRed},
+}
+
+func TestLevelTableLookup(t *testing.T) {
+	table := newLevelTable(customLevels)
+
+	tests := []struct {
+		level    slog.Level
+		expected string
+	}{
+		{level: levelTrace, expected: "TRC"},
+		{level: levelTrace - 2, expected: "TRC-2"},
+		{level: slog.LevelDebug + 1, expected: "DBG+1"},
+		{level: levelNotice, expected: "NTC"},
+		{level: slog.LevelWarn + 2, expected: "WRN+2"},
+		{level: slog.LevelError, expected: "ERR"},
+		{level: levelFatal, expected: "FTL"},
+		{level: levelFatal + 4, expected: "FTL+4"},
+	}
+
+	for _, tt := range tests {
+		style, offset := table.lookup(tt.level)
+		if got := string(appendLevelLabel(nil, style, offset)); got != tt.expected {
+			t.Errorf("Expected label of level %d to be '%s', got '%s'", tt.level, tt.expected, got)
+		}
+	}
+}
+
+func TestDefaultLevelTable(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected string
+	}{
+		{level: slog.LevelDebug - 4, expected: "DBG-4"},
+		{level: slog.LevelInfo + 2, expected: "INF+2"},
+		{level: slog.LevelWarn + 2, expected: "WRN+2"},
+		{level: slog.LevelError + 4, expected: "ERR+4"},
+	}
+
+	for _, tt := range tests {
+		style, offset := newLevelTable(nil).lookup(tt.level)
+		if got := string(appendLevelLabel(nil, style, offset)); got != tt.expected {
+			t.Errorf("Expected label of level %d to be '%s', got '%s'", tt.level, tt.expected, got)
+		}
+	}
+}
+
+func TestEncodeCustomLevel(t *testing.T) {
+	t.Run("should write custom label with color", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{Levels: customLevels})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeLevel(buf, levelNotice)
+		expected := txtCyan + "NTC " + txtReset
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should write in-between level with offset", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeLevel(buf, slog.LevelWarn+2)
+		expected := txtYellow + "WRN+2 " + txtReset
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should write custom emoji", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{Levels: customLevels})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeEmojiLevel(buf, levelFatal)
+		expected := "💀 "
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should not write emoji when style has none", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{Levels: customLevels})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeEmojiLevel(buf, levelTrace)
+		if string(*buf) != "" {
+			t.Errorf("Expected buffer to be empty, got '%s'", string(*buf))
+		}
+	})
+
+	t.Run("should write custom label in json", func(t *testing.T) {
+		encoder := newEncodeJSON(HandlerOptions{Levels: customLevels})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeLevel(buf, levelTrace-1)
+		expected := `"level":"TRC-1",`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should keep slog level names in json by default", func(t *testing.T) {
+		encoder := newEncodeJSON(HandlerOptions{})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeLevel(buf, slog.LevelWarn+2)
+		expected := `"level":"WARN+2",`
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+}
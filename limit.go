@@ -0,0 +1,82 @@
+package slogja
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// depthMarker replaces a collection nested deeper than MaxDepth.
+const depthMarker = "…"
+
+// TruncatedKey is the key of the attribute written in place of the
+// attributes dropped by MaxRecordBytes. Its value is their number, written
+// as an int so that MaxStringLength does not apply to it.
+const TruncatedKey = "truncated"
+
+// appendMore appends the marker for n elements, bytes or attributes that
+// were left out, e.g. "…(+120 more)".
+func appendMore(b []byte, n int) []byte {
+	b = append(b, "…(+"...)
+	b = strconv.AppendInt(b, int64(n), 10)
+	return append(b, " more)"...)
+}
+
+// limitElements returns how many of n elements fit in max, the
+// MaxElements. A max of zero or less means no limit.
+func limitElements(max, n int) int {
+	if max > 0 && n > max {
+		return max
+	}
+	return n
+}
+
+// truncateString cuts s to at most max bytes, on a rune boundary, and
+// appends the marker for the bytes left out. A max of zero or less means
+// no limit.
+func truncateString(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return string(appendMore([]byte(s[:n]), len(s)-n))
+}
+
+// exceedsRecordBytes reports whether the attribute written to buf after
+// mark makes the record larger than MaxRecordBytes, and if so cuts it off.
+func exceedsRecordBytes(opts HandlerOptions, buf *buffer, mark int) bool {
+	if opts.MaxRecordBytes <= 0 || len(*buf) <= opts.MaxRecordBytes {
+		return false
+	}
+	*buf = (*buf)[:mark]
+	return true
+}
+
+// writeMessageLimited writes the message msg with write, cut to
+// MaxStringLength and, when the record would otherwise exceed
+// MaxRecordBytes, further until it fits or only its first rune is left.
+// Everything written to buf before counts toward the record.
+func writeMessageLimited(opts HandlerOptions, buf *buffer, msg string, write func(buf *buffer, s string)) {
+	mark := len(*buf)
+	limit := opts.MaxStringLength
+	for {
+		write(buf, truncateString(msg, limit))
+
+		over := len(*buf) - opts.MaxRecordBytes
+		if opts.MaxRecordBytes <= 0 || over <= 0 {
+			return
+		}
+		n := len(msg)
+		if limit > 0 {
+			n = min(n, limit)
+		}
+		if n <= 1 {
+			return
+		}
+		limit = max(n-over, 1)
+		*buf = (*buf)[:mark]
+	}
+}
@@ -0,0 +1,282 @@
+package slogja
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		max      int
+		expected string
+	}{
+		{name: "should keep string without limit", s: "hello", max: 0, expected: "hello"},
+		{name: "should keep short string", s: "hello", max: 5, expected: "hello"},
+		{name: "should cut long string", s: "hello world", max: 5, expected: "hello…(+6 more)"},
+		{name: "should cut on rune boundary", s: "héllo", max: 2, expected: "h…(+5 more)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateString(tt.s, tt.max); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteAnyLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		opt   HandlerOptions
+		value any
+		text  string
+		json  string
+	}{
+		{
+			name:  "should limit slice elements",
+			opt:   HandlerOptions{MaxElements: 2},
+			value: []int{1, 2, 3, 4, 5},
+			text:  `[1 2 …(+3 more)]`,
+			json:  `[1,2,"…(+3 more)"]`,
+		},
+		{
+			name:  "should limit group members",
+			opt:   HandlerOptions{MaxElements: 1},
+			value: []slog.Value{slog.GroupValue(slog.Int("a", 1), slog.Int("b", 2))},
+			text:  `[{a:1 …(+1 more)}]`,
+			json:  `[{"a":1,"…":"…(+1 more)"}]`,
+		},
+		{
+			name:  "should limit map entries",
+			opt:   HandlerOptions{MaxElements: 1},
+			value: map[string]int{"a": 1, "b": 2, "c": 3},
+		},
+		{
+			name:  "should limit depth",
+			opt:   HandlerOptions{MaxDepth: 2},
+			value: [][][]int{{{1}}, {{}}},
+			text:  `[[…] […]]`,
+			json:  `[["…"],["…"]]`,
+		},
+		{
+			name: "should limit nested string length",
+			opt:  HandlerOptions{MaxStringLength: 3},
+			value: struct {
+				S string
+				E error
+			}{S: "abcdef", E: panicUnwrap{}},
+			text: `{S:"abc…(+3 more)" E:"out…(+2 more)"}`,
+			json: `{"S":"abc…(+3 more)","E":"out…(+2 more)"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer()
+			defer buf.Free()
+
+			opt := tt.opt
+			opt.DisableColor = true
+			newEncodeText(opt).writeAny(buf, reflect.ValueOf(tt.value))
+			if tt.text != "" && string(*buf) != tt.text {
+				t.Errorf("Expected text to contain '%s', got '%s'", tt.text, string(*buf))
+			}
+			if !strings.Contains(string(*buf), "…") {
+				t.Errorf("Expected text to contain a truncation marker, got '%s'", string(*buf))
+			}
+
+			*buf = (*buf)[:0]
+			newEncodeJSON(tt.opt).writeAny(buf, reflect.ValueOf(tt.value))
+			if tt.json != "" && string(*buf) != tt.json {
+				t.Errorf("Expected json to contain '%s', got '%s'", tt.json, string(*buf))
+			}
+			if !json.Valid(*buf) {
+				t.Errorf("Expected valid json, got '%s'", string(*buf))
+			}
+		})
+	}
+}
+
+func TestHandlerLimits(t *testing.T) {
+	t.Run("should limit top-level string in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor:    true,
+			DisableEmoji:    true,
+			DisableTime:     true,
+			MaxStringLength: 4,
+		}))
+
+		l.Info("msg", "s", "truncated")
+
+		expected := `INF "msg" s="trun…(+5 more)" ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should drop attributes over MaxRecordBytes in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor:   true,
+			DisableEmoji:   true,
+			DisableTime:    true,
+			MaxRecordBytes: 20,
+		}))
+
+		l.Info("msg", "a", 1, "big", strings.Repeat("x", 100), "b", 2)
+
+		expected := `INF "msg" a=1 truncated=2 ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should limit message length", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor:    true,
+			DisableEmoji:    true,
+			DisableTime:     true,
+			MaxStringLength: 4,
+		}))
+
+		l.Info("message", "s", "ok")
+
+		expected := `INF "mess…(+3 more)" s="ok" ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should cut message to MaxRecordBytes in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor:   true,
+			DisableEmoji:   true,
+			DisableTime:    true,
+			MaxRecordBytes: 100,
+		}))
+
+		l.Info(strings.Repeat("x", 200), "a", 1)
+
+		out := b.String()
+		if len(out) > 100+len(" truncated=1 \n") {
+			t.Errorf("Expected record of at most 100 bytes before the truncated attribute, got %d: '%s'", len(out), out)
+		}
+		if !strings.HasSuffix(out, " more)\" truncated=1 \n") {
+			t.Errorf("Expected cut message and truncated attribute, got '%s'", out)
+		}
+	})
+
+	t.Run("should cut message to MaxRecordBytes in json handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, &HandlerOptions{
+			DisableTime:    true,
+			MaxRecordBytes: 100,
+		}))
+
+		l.Info(strings.Repeat("x", 200))
+
+		out := b.Bytes()
+		if len(out) > 100+len("}\n") {
+			t.Errorf("Expected record of at most 100 bytes, got %d: '%s'", len(out), out)
+		}
+		if !json.Valid(out) || !bytes.Contains(out, []byte("…(+")) {
+			t.Errorf("Expected valid json with cut message, got '%s'", out)
+		}
+	})
+
+	t.Run("should not cut the truncated count with MaxStringLength", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor:    true,
+			DisableEmoji:    true,
+			DisableTime:     true,
+			MaxStringLength: 5,
+			MaxRecordBytes:  20,
+		}))
+
+		l.Info("msg", "a", 1, "big", strings.Repeat("x", 100), "b", 2)
+
+		expected := `INF "msg" a=1 truncated=2 ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should limit top-level group members in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			DisableTime:  true,
+			MaxElements:  2,
+		}))
+
+		l.Info("msg", slog.Group("g", "a", 1, "b", 2, "c", 3, "d", 4))
+
+		expected := `INF "msg" g.a=1 g.b=2 g.…="…(+2 more)" ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should limit top-level group members in pretty mode", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			DisableTime:  true,
+			Pretty:       PrettyAlways,
+			MaxElements:  1,
+		}))
+
+		l.Info("msg", slog.Group("g", "a", 1, "b", 2))
+
+		expected := `INF "msg" ` + "\n" +
+			"└─ g\n" +
+			"   ├─ a=1\n" +
+			"   └─ …=\"…(+1 more)\"\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should limit top-level group members in json handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, &HandlerOptions{
+			DisableTime: true,
+			MaxElements: 2,
+		}))
+
+		l.Info("msg", slog.Group("g", "a", 1, "b", 2, "c", 3, "d", 4))
+
+		expected := `{"level":"INFO","msg":"msg","g":{"a":1,"b":2,"…":"…(+2 more)"}}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should drop attributes over MaxRecordBytes in json handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, &HandlerOptions{
+			DisableTime:    true,
+			MaxRecordBytes: 40,
+		}))
+
+		l.WithGroup("g").Info("msg", "a", 1, "big", strings.Repeat("x", 100), "b", 2)
+
+		expected := `{"level":"INFO","msg":"msg","g":{"a":1,"truncated":2}}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+}
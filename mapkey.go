@@ -0,0 +1,117 @@
+package slogja
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// mapEntry is a key and value read from a map. Reading both while iterating
+// keeps keys such as NaN, which MapIndex cannot find, paired with their value.
+type mapEntry struct {
+	key, value reflect.Value
+}
+
+func mapEntries(val reflect.Value) []mapEntry {
+	entries := make([]mapEntry, 0, val.Len())
+	iter := val.MapRange()
+	for iter.Next() {
+		entries = append(entries, mapEntry{key: iter.Key(), value: iter.Value()})
+	}
+	return entries
+}
+
+// sortMapEntries sorts map entries by key so that a map is written the same
+// way every time. Strings, numbers and bools compare by value, arrays element by
+// element, and types with a Compare method, such as time.Time, by that
+// method. Anything else, and keys of different types in a map with
+// interface keys, falls back to comparing the formatted key.
+func sortMapEntries(entries []mapEntry) {
+	slices.SortStableFunc(entries, func(a, b mapEntry) int {
+		return compareKeys(a.key, b.key)
+	})
+}
+
+func compareKeys(a, b reflect.Value) int {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return cmp.Compare(formatKey(a), formatKey(b))
+	}
+
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		default:
+			return 1
+		}
+	case reflect.Array:
+		for i := range a.Len() {
+			if c := compareKeys(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return cmp.Compare(a.Pointer(), b.Pointer())
+	}
+
+	if c, ok := callCompare(a, b); ok {
+		return c
+	}
+	return cmp.Compare(formatKey(a), formatKey(b))
+}
+
+// callCompare calls a.Compare(b) when a has a method of the form
+// Compare(T) int, and reports whether it did.
+func callCompare(a, b reflect.Value) (c int, ok bool) {
+	if !a.CanInterface() || !b.CanInterface() {
+		return 0, false
+	}
+
+	m := a.MethodByName("Compare")
+	if !m.IsValid() {
+		return 0, false
+	}
+	mt := m.Type()
+	if mt.NumIn() != 1 || mt.In(0) != a.Type() || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Int {
+		return 0, false
+	}
+
+	defer func() {
+		if recover() != nil {
+			c, ok = 0, false
+		}
+	}()
+	return int(m.Call([]reflect.Value{b})[0].Int()), true
+}
+
+// formatKey formats a key for the fallback comparison. The type is included
+// so that keys of different types are grouped together.
+func formatKey(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if !v.CanInterface() {
+		return v.Type().String()
+	}
+	return fmt.Sprintf("%T %v", v.Interface(), v.Interface())
+}
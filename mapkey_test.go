@@ -0,0 +1,113 @@
+package slogja
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type pointKey struct{ X, Y int }
+
+func TestSortMapKeys(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value any
+		text  string
+		json  string
+	}{
+		{
+			name:  "should sort string keys",
+			value: map[string]int{"b": 2, "c": 3, "a": 1},
+			text:  `["a":1 "b":2 "c":3]`,
+			json:  `{"a":1,"b":2,"c":3}`,
+		},
+		{
+			name:  "should sort int keys numerically",
+			value: map[int]bool{10: true, -1: false, 2: true},
+			text:  `[-1:false 2:true 10:true]`,
+			json:  `{"-1":false,"2":true,"10":true}`,
+		},
+		{
+			name:  "should sort float keys with NaN first",
+			value: map[float64]int{1.5: 1, math.NaN(): 0, -2: 2},
+			text:  `[NaN:0 -2:2 1.5:1]`,
+			json:  `{"NaN":0,"-2":2,"1.5":1}`,
+		},
+		{
+			name:  "should sort bool keys",
+			value: map[bool]int{true: 1, false: 0},
+			text:  `[false:0 true:1]`,
+			json:  `{"false":0,"true":1}`,
+		},
+		{
+			name:  "should sort array keys element by element",
+			value: map[[2]int]int{{1, 2}: 2, {0, 9}: 1, {1, 0}: 3},
+			text:  `[[0 9]:1 [1 0]:3 [1 2]:2]`,
+			json:  `{"[0,9]":1,"[1,0]":3,"[1,2]":2}`,
+		},
+		{
+			name:  "should sort keys with Compare method",
+			value: map[time.Time]int{t0.Add(time.Hour): 2, t0: 1},
+		},
+		{
+			name:  "should sort struct keys by formatted value",
+			value: map[pointKey]int{{2, 1}: 3, {1, 2}: 1, {1, 3}: 2},
+			text:  `[{X:1 Y:2}:1 {X:1 Y:3}:2 {X:2 Y:1}:3]`,
+			json:  `{"{\"X\":1,\"Y\":2}":1,"{\"X\":1,\"Y\":3}":2,"{\"X\":2,\"Y\":1}":3}`,
+		},
+		{
+			name:  "should group interface keys by type",
+			value: map[any]int{"b": 4, 2: 2, "a": 3, 1: 1},
+			text:  `[1:1 2:2 "a":3 "b":4]`,
+			json:  `{"1":1,"2":2,"a":3,"b":4}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Run a few times so that random map order would show up.
+			for range 10 {
+				buf := newBuffer()
+
+				newEncodeText(HandlerOptions{DisableColor: true}).writeAny(buf, reflect.ValueOf(tt.value))
+				text := string(*buf)
+
+				*buf = (*buf)[:0]
+				newEncodeJSON(HandlerOptions{}).writeAny(buf, reflect.ValueOf(tt.value))
+				json := string(*buf)
+				buf.Free()
+
+				if tt.text == "" {
+					tt.text, tt.json = text, json
+				}
+				if text != tt.text {
+					t.Fatalf("Expected text to contain '%s', got '%s'", tt.text, text)
+				}
+				if json != tt.json {
+					t.Fatalf("Expected json to contain '%s', got '%s'", tt.json, json)
+				}
+			}
+		})
+	}
+
+	t.Run("should sort time keys chronologically", func(t *testing.T) {
+		entries := []mapEntry{{key: reflect.ValueOf(t0.Add(time.Hour))}, {key: reflect.ValueOf(t0)}}
+		sortMapEntries(entries)
+		if !entries[0].key.Interface().(time.Time).Equal(t0) {
+			t.Errorf("Expected '%v' first, got '%v'", t0, entries[0].key)
+		}
+	})
+}
+
+func TestUnsortedMapKeys(t *testing.T) {
+	buf := newBuffer()
+	defer buf.Free()
+
+	newEncodeJSON(HandlerOptions{UnsortedMapKeys: true}).writeAny(buf, reflect.ValueOf(map[string]int{"a": 1, "b": 2}))
+	if got := string(*buf); got != `{"a":1,"b":2}` && got != `{"b":2,"a":1}` {
+		t.Errorf("Expected both entries, got '%s'", got)
+	}
+}
@@ -0,0 +1,163 @@
+package slogja
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// PrettyMode selects when the text handler writes the attributes of a
+// record as a tree below the message line, one attribute per line.
+type PrettyMode int
+
+const (
+	// PrettyOff keeps every record on a single line.
+	PrettyOff PrettyMode = iota
+	// PrettyAuto writes the tree only when the single line would be wider
+	// than PrettyWidth.
+	PrettyAuto
+	// PrettyAlways writes every record with attributes as a tree.
+	PrettyAlways
+)
+
+const defaultPrettyWidth = 120
+
+// groupedAttr is an attribute added with WithAttrs and the groups that were
+// open at the time, kept to build the tree of PrettyMode.
+type groupedAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+// treeNode is an attribute or a group in the tree of PrettyMode, or with
+// more set the marker for the members of a group left out by MaxElements.
+type treeNode struct {
+	key      string
+	val      slog.Value
+	group    bool
+	more     int
+	children []*treeNode
+}
+
+// addTreeAttr adds a below the groups gs of nodes. A group is merged into
+// the last node when that is the same group, so that attributes written
+// under WithGroup end up together. Groups are limited by MaxElements as on
+// a single line.
+func (e *encodeText) addTreeAttr(nodes []*treeNode, gs []string, a slog.Attr) []*treeNode {
+	if a.Equal(slog.Attr{}) {
+		return nodes
+	}
+	if len(gs) > 0 && gs[0] == "" {
+		return e.addTreeAttr(nodes, gs[1:], a)
+	}
+	if len(gs) > 0 {
+		if n := len(nodes); n > 0 && nodes[n-1].group && nodes[n-1].key == gs[0] {
+			nodes[n-1].children = e.addTreeAttr(nodes[n-1].children, gs[1:], a)
+			return nodes
+		}
+		children := e.addTreeAttr(nil, gs[1:], a)
+		if len(children) == 0 {
+			return nodes
+		}
+		return append(nodes, &treeNode{key: gs[0], group: true, children: children})
+	}
+
+	val := a.Value.Resolve()
+	if val.Kind() != slog.KindGroup {
+		return append(nodes, &treeNode{key: a.Key, val: val})
+	}
+
+	// As on a single line, a group with an empty key is inlined into its
+	// parent and an empty group is left out.
+	attrs := val.Group()
+	n := limitElements(e.opt.MaxElements, len(attrs))
+	if a.Key == "" {
+		for _, subAttr := range attrs[:n] {
+			nodes = e.addTreeAttr(nodes, nil, subAttr)
+		}
+		return appendMoreNode(nodes, len(attrs)-n)
+	}
+	g := &treeNode{key: a.Key, group: true}
+	for _, subAttr := range attrs[:n] {
+		g.children = e.addTreeAttr(g.children, nil, subAttr)
+	}
+	g.children = appendMoreNode(g.children, len(attrs)-n)
+	if len(g.children) == 0 {
+		return nodes
+	}
+	return append(nodes, g)
+}
+
+// appendMoreNode appends the marker for n members left out, if any.
+func appendMoreNode(nodes []*treeNode, n int) []*treeNode {
+	if n == 0 {
+		return nodes
+	}
+	return append(nodes, &treeNode{key: "…", more: n})
+}
+
+// usePretty reports whether the attributes written on the line in buf
+// should be written as a tree instead.
+func (e *encodeText) usePretty(buf *buffer) bool {
+	switch e.opt.Pretty {
+	case PrettyAlways:
+		return true
+	case PrettyAuto:
+		width := e.opt.PrettyWidth
+		if width <= 0 {
+			width = defaultPrettyWidth
+		}
+		for line := range strings.Lines(string(*buf)) {
+			if stringWidth(strings.TrimRight(line, " \n")) > width {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeTree writes nodes one per line, each after prefix and a box-drawing
+// guide, with the members of groups indented below them.
+func (e *encodeText) writeTree(buf *buffer, nodes []*treeNode, prefix string) {
+	for i, n := range nodes {
+		last := i == len(nodes)-1
+
+		e.style(buf, e.theme.Separator)
+		buf.WriteString(prefix)
+		if last {
+			buf.WriteString("└─ ")
+		} else {
+			buf.WriteString("├─ ")
+		}
+		e.endStyle(buf, e.theme.Separator)
+
+		if n.more > 0 {
+			e.writeKey(buf, nil, n.key)
+			e.style(buf, e.theme.Separator)
+			e.writeStringer(buf, string(appendMore(nil, n.more)))
+			e.endStyle(buf, e.theme.Separator)
+			e.writeNewline(buf)
+			continue
+		}
+		if !n.group {
+			e.writeKey(buf, nil, n.key)
+			e.writeRawValue(buf, n.val)
+			e.writeNewline(buf)
+			continue
+		}
+
+		e.style(buf, e.theme.Key)
+		if needsQuoting(n.key) {
+			*buf = strconv.AppendQuote(*buf, n.key)
+		} else {
+			buf.WriteString(n.key)
+		}
+		e.endStyle(buf, e.theme.Key)
+		e.writeNewline(buf)
+		if last {
+			e.writeTree(buf, n.children, prefix+"   ")
+		} else {
+			e.writeTree(buf, n.children, prefix+"│  ")
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package slogja
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestHandlerPretty(t *testing.T) {
+	tests := []struct {
+		name     string
+		opt      HandlerOptions
+		handler  func(h slog.Handler) slog.Handler
+		args     []any
+		expected string
+	}{
+		{
+			name: "should write attributes as a tree",
+			opt:  HandlerOptions{Pretty: PrettyAlways},
+			args: []any{
+				"a", 1,
+				slog.Group("g", slog.String("b", "x"), slog.Group("h", "c", true)),
+				"d", []int{1, 2},
+			},
+			expected: `INF "msg" ` + "\n" +
+				"├─ a=1\n" +
+				"├─ g\n" +
+				"│  ├─ b=\"x\"\n" +
+				"│  └─ h\n" +
+				"│     └─ c=true\n" +
+				"└─ d=[1 2]\n",
+		},
+		{
+			name: "should nest attributes under handler groups",
+			opt:  HandlerOptions{Pretty: PrettyAlways},
+			handler: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.Int("pre", 1)}).WithGroup("req").WithAttrs([]slog.Attr{slog.String("id", "r1")})
+			},
+			args: []any{"path", "/x"},
+			expected: `INF "msg" ` + "\n" +
+				"├─ pre=1\n" +
+				"└─ req\n" +
+				"   ├─ id=\"r1\"\n" +
+				"   └─ path=\"/x\"\n",
+		},
+		{
+			name:     "should leave out empty groups and inline empty keys",
+			opt:      HandlerOptions{Pretty: PrettyAlways},
+			args:     []any{slog.Group("empty"), slog.Group("", "a", 1)},
+			expected: `INF "msg" ` + "\n" + "└─ a=1\n",
+		},
+		{
+			name:     "should keep short lines in auto mode",
+			opt:      HandlerOptions{Pretty: PrettyAuto, PrettyWidth: 20},
+			args:     []any{"a", 1},
+			expected: `INF "msg" a=1 ` + "\n",
+		},
+		{
+			name: "should switch long lines in auto mode",
+			opt:  HandlerOptions{Pretty: PrettyAuto, PrettyWidth: 20},
+			args: []any{"a", 1, "b", "a value that is long"},
+			expected: `INF "msg" ` + "\n" +
+				"├─ a=1\n" +
+				"└─ b=\"a value that is long\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			tt.opt.DisableColor = true
+			tt.opt.DisableEmoji = true
+			tt.opt.DisableTime = true
+
+			var h slog.Handler = NewTextHandler(b, &tt.opt)
+			if tt.handler != nil {
+				h = tt.handler(h)
+			}
+
+			rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			rec.Add(tt.args...)
+			if err := h.Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if b.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package slogja
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QuoteMode controls when the text handler wraps strings in double quotes.
+// Strings are always escaped in strconv style, so control characters and
+// terminal escape sequences can never reach the output verbatim.
+type QuoteMode int
+
+const (
+	// QuoteAlways quotes every string value and message.
+	QuoteAlways QuoteMode = iota
+	// QuoteAuto quotes a string only when it is empty or contains spaces,
+	// '=', '"', control characters or other bytes that would break a
+	// logfmt style line.
+	QuoteAuto
+)
+
+// needsQuoting reports whether s must be quoted to be read back unambiguously.
+func needsQuoting(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b <= ' ' || b == '=' || b == '"' || b == '\\' || b == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// appendQuotedInner appends s escaped as by strconv.Quote but without the
+// surrounding quotes, so several parts can share one pair of quotes.
+func appendQuotedInner(b []byte, s string) []byte {
+	n := len(b)
+	b = strconv.AppendQuote(b, s)
+	return append(b[:n], b[n+1:len(b)-1]...)
+}
+
+// appendEscaped appends s without surrounding quotes, escaping control
+// characters, non-printable runes and invalid UTF-8 the way strconv.Quote
+// does. Printable text, including '"', is copied as is.
+func appendEscaped(b []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		invalid := r == utf8.RuneError && size == 1
+		if !invalid && strconv.IsPrint(r) {
+			i += size
+			continue
+		}
+
+		b = append(b, s[start:i]...)
+		switch {
+		case r == '\n':
+			b = append(b, `\n`...)
+		case r == '\r':
+			b = append(b, `\r`...)
+		case r == '\t':
+			b = append(b, `\t`...)
+		case invalid || r < 0x80:
+			b = append(b, `\x`...)
+			b = append(b, hexDigits[s[i]>>4], hexDigits[s[i]&0xF])
+		case r < 0x10000:
+			b = append(b, `\u`...)
+			for shift := 12; shift >= 0; shift -= 4 {
+				b = append(b, hexDigits[r>>shift&0xF])
+			}
+		default:
+			b = append(b, `\U`...)
+			for shift := 28; shift >= 0; shift -= 4 {
+				b = append(b, hexDigits[r>>shift&0xF])
+			}
+		}
+		i += size
+		start = i
+	}
+	return append(b, s[start:]...)
+}
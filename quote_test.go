@@ -0,0 +1,53 @@
+package slogja
+
+import "testing"
+
+func TestNeedsQuoting(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "", expected: true},
+		{input: "value", expected: false},
+		{input: "a.b-c_d/e:f", expected: false},
+		{input: "สวัสดี", expected: false},
+		{input: "hello world", expected: true},
+		{input: "a=b", expected: true},
+		{input: `a"b`, expected: true},
+		{input: `a\b`, expected: true},
+		{input: "a\nb", expected: true},
+		{input: "\x1b[31m", expected: true},
+		{input: "a\u2028b", expected: true},
+		{input: "a\u202eb", expected: true},
+		{input: "a\xffb", expected: true},
+	}
+
+	for _, tt := range tests {
+		if got := needsQuoting(tt.input); got != tt.expected {
+			t.Errorf("Expected needsQuoting(%q) to be %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestAppendEscaped(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "plain text", expected: "plain text"},
+		{input: `keep "quotes"`, expected: `keep "quotes"`},
+		{input: "line\nbreak\r\ttab", expected: `line\nbreak\r\ttab`},
+		{input: "\x1b[2J\x1b[31mred", expected: `\x1b[2J\x1b[31mred`},
+		{input: "bell\a", expected: `bell\x07`},
+		{input: "csi\u009b31m", expected: `csi\u009b31m`},
+		{input: "rtl\u202eoverride", expected: `rtl\u202eoverride`},
+		{input: "bad\xffbyte", expected: `bad\xffbyte`},
+		{input: "ok \ufffd 🌱", expected: "ok \ufffd 🌱"},
+	}
+
+	for _, tt := range tests {
+		if got := string(appendEscaped(nil, tt.input)); got != tt.expected {
+			t.Errorf("Expected appendEscaped(%q) to be '%s', got '%s'", tt.input, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package slogja
+
+import (
+	"encoding"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// TypeRenderer writes the values of one type as the slog.Value returned by
+// Render. Renderers set in HandlerOptions.Renderers take precedence over
+// everything else, including LogValue and the built-in renderers. Create
+// one with RenderType.
+type TypeRenderer struct {
+	Type   reflect.Type
+	Render func(v any) slog.Value
+}
+
+// RenderType returns a TypeRenderer for values of type T.
+func RenderType[T any](render func(v T) slog.Value) TypeRenderer {
+	return TypeRenderer{
+		Type:   reflect.TypeFor[T](),
+		Render: func(v any) slog.Value { return render(v.(T)) },
+	}
+}
+
+// renderFunc renders a value found by writeAny.
+type renderFunc func(val reflect.Value) slog.Value
+
+// builtinRenderers hold the standard library types whose reflected form is
+// useless in a log line and that have no String or MarshalText method on
+// the value itself. A URL is written with its password redacted.
+var builtinRenderers = func() map[reflect.Type]func(reflect.Value) string {
+	m := make(map[reflect.Type]func(reflect.Value) string)
+	addPtrRenderer(m, func(n *net.IPNet) string { return n.String() })
+	addPtrRenderer(m, func(u *url.URL) string { return u.Redacted() })
+	addPtrRenderer(m, func(i *big.Int) string { return i.String() })
+	addPtrRenderer(m, func(f *big.Float) string { return f.Text('g', -1) })
+	addPtrRenderer(m, func(r *big.Rat) string { return r.RatString() })
+	addPtrRenderer(m, func(l *time.Location) string { return l.String() })
+	return m
+}()
+
+// addPtrRenderer registers fn, a method with a pointer receiver, for both
+// *T and T. Values of T that are not addressable are copied.
+func addPtrRenderer[T any](m map[reflect.Type]func(reflect.Value) string, fn func(*T) string) {
+	m[reflect.TypeFor[*T]()] = func(val reflect.Value) string {
+		return fn(val.Interface().(*T))
+	}
+	m[reflect.TypeFor[T]()] = func(val reflect.Value) string {
+		if val.CanAddr() {
+			return fn(val.Addr().Interface().(*T))
+		}
+		v := val.Interface().(T)
+		return fn(&v)
+	}
+}
+
+var (
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	stringerType      = reflect.TypeFor[fmt.Stringer]()
+)
+
+// render writes val as the slog.Value returned by fn, a renderer from
+// HandlerOptions.Renderers.
+func (w *anyWalker) render(buf *buffer, fn renderFunc, val reflect.Value) {
+	v, panicked := callRender(fn, val)
+	if panicked != "" {
+		w.e.writeStringer(buf, panicked)
+		return
+	}
+	w.walkValue(buf, v)
+}
+
+// methodEncoder returns the encoder of the values of t written with a
+// built-in renderer, their MarshalText or String method, or as a UUID, or
+// nil if t has none of these.
+func methodEncoder(t reflect.Type) encoderFunc {
+	if fn, ok := builtinRenderers[t]; ok {
+		return func(w *anyWalker, buf *buffer, val reflect.Value) {
+			s := callString("String", func() string { return fn(val) })
+			w.e.writeStringer(buf, truncateString(s, w.opt.MaxStringLength))
+		}
+	}
+
+	switch {
+	case ownsMethod(t, textMarshalerType):
+		return (*anyWalker).walkTextMarshaler
+	case ownsMethod(t, stringerType):
+		return (*anyWalker).walkStringer
+	case t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 && t.Name() != "":
+		// A named [16]byte without a String method is taken to be a UUID.
+		return (*anyWalker).walkUUID
+	}
+	return nil
+}
+
+// ownsMethod reports whether t implements iface and, for a pointer, whether
+// it does so only through methods with a pointer receiver. A pointer to a
+// type with value methods is followed and the value written instead.
+func ownsMethod(t, iface reflect.Type) bool {
+	if !t.Implements(iface) {
+		return false
+	}
+	return t.Kind() != reflect.Ptr || !t.Elem().Implements(iface)
+}
+
+func (w *anyWalker) walkTextMarshaler(buf *buffer, val reflect.Value) {
+	text, err := marshalText(interfaceOf(val).(encoding.TextMarshaler))
+	if err != nil {
+		w.e.writeError(buf, truncateString(errorMessage(err), w.opt.MaxStringLength))
+		return
+	}
+	w.e.writeStringer(buf, truncateString(text, w.opt.MaxStringLength))
+}
+
+func (w *anyWalker) walkStringer(buf *buffer, val reflect.Value) {
+	s := callString("String", interfaceOf(val).(fmt.Stringer).String)
+	w.e.writeStringer(buf, truncateString(s, w.opt.MaxStringLength))
+}
+
+func (w *anyWalker) walkUUID(buf *buffer, val reflect.Value) {
+	w.e.writeStringer(buf, formatUUID(bytesOf(val)))
+}
+
+// callRender calls fn, turning a panic into a !PANIC marker.
+func callRender(fn renderFunc, val reflect.Value) (v slog.Value, panicked string) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = panicMarker("Render", r)
+		}
+	}()
+	return fn(val), ""
+}
+
+// marshalText calls m.MarshalText, turning a panic into a !PANIC marker.
+func marshalText(m encoding.TextMarshaler) (s string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s, err = panicMarker("MarshalText", r), nil
+		}
+	}()
+	b, err := m.MarshalText()
+	return string(b), err
+}
+
+// formatUUID formats 16 bytes in the canonical 8-4-4-4-12 form.
+func formatUUID(b []byte) string {
+	buf := make([]byte, 0, 36)
+	for i, c := range b {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			buf = append(buf, '-')
+		}
+		buf = append(buf, hexDigits[c>>4], hexDigits[c&0xF])
+	}
+	return string(buf)
+}
@@ -0,0 +1,97 @@
+package slogja
+
+import (
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// SourceFormat controls how the text handler renders the source location
+// when HandlerOptions.AddSource is set.
+type SourceFormat int
+
+const (
+	// SourceShort renders the file's parent directory, file name and line,
+	// e.g. "slogja/handler.go:42".
+	SourceShort SourceFormat = iota
+	// SourceLong renders the full file path and line.
+	SourceLong
+	// SourceFunc renders the package qualified function name,
+	// e.g. "slogja.(*textHandler).Handle".
+	SourceFunc
+)
+
+// recordSource returns the source location of the program counter recorded
+// by slog, or nil when the record has none.
+func recordSource(pc uintptr) *slog.Source {
+	if pc == 0 {
+		return nil
+	}
+
+	fs := runtime.CallersFrames([]uintptr{pc})
+	f, _ := fs.Next()
+	return &slog.Source{
+		Function: f.Function,
+		File:     f.File,
+		Line:     f.Line,
+	}
+}
+
+// appendSource appends src to b in the given format.
+func appendSource(b []byte, src *slog.Source, format SourceFormat) []byte {
+	switch format {
+	case SourceFunc:
+		fn := src.Function
+		if i := strings.LastIndexByte(fn, '/'); i >= 0 {
+			fn = fn[i+1:]
+		}
+		return append(b, fn...)
+	case SourceLong:
+		b = append(b, src.File...)
+	default:
+		b = append(b, shortFile(src.File)...)
+	}
+	b = append(b, ':')
+	return strconv.AppendInt(b, int64(src.Line), 10)
+}
+
+// shortFile trims a path to its last directory and file name.
+func shortFile(file string) string {
+	i := strings.LastIndexByte(file, '/')
+	if i < 0 {
+		return file
+	}
+	if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+		return file[j+1:]
+	}
+	return file
+}
+
+// sourceGroup expands src into the group written by structured handlers.
+func sourceGroup(key string, src *slog.Source) slog.Attr {
+	return slog.Group(key,
+		slog.String("function", src.Function),
+		slog.String("file", src.File),
+		slog.Int("line", src.Line),
+	)
+}
+
+// sourceAttr builds the source attribute for a record and passes it through
+// ReplaceAttr. It returns an empty Attr when no source should be written.
+func sourceAttr(opts HandlerOptions, pc uintptr) slog.Attr {
+	if !opts.AddSource {
+		return slog.Attr{}
+	}
+
+	src := recordSource(pc)
+	if src == nil {
+		return slog.Attr{}
+	}
+
+	a := slog.Any(slog.SourceKey, src)
+	if rep := opts.ReplaceAttr; rep != nil {
+		a = rep(nil, a)
+	}
+	return a
+}
@@ -0,0 +1,80 @@
+package slogja
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestAppendSource(t *testing.T) {
+	src := &slog.Source{
+		Function: "github.com/kongsakchai/slogja/example.main",
+		File:     "/home/dev/slogja/example/main.go",
+		Line:     42,
+	}
+
+	tests := []struct {
+		name     string
+		format   SourceFormat
+		expected string
+	}{
+		{name: "should write short path when SourceShort", format: SourceShort, expected: "example/main.go:42"},
+		{name: "should write full path when SourceLong", format: SourceLong, expected: "/home/dev/slogja/example/main.go:42"},
+		{name: "should write function name when SourceFunc", format: SourceFunc, expected: "example.main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(appendSource(nil, src, tt.format))
+			if got != tt.expected {
+				t.Errorf("Expected source to be '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestShortFile(t *testing.T) {
+	tests := map[string]string{
+		"main.go":          "main.go",
+		"/main.go":         "/main.go",
+		"/a/b/c/main.go":   "c/main.go",
+		"example/main.go":  "example/main.go",
+		"C:/dev/x/main.go": "x/main.go",
+	}
+
+	for input, expected := range tests {
+		if got := shortFile(input); got != expected {
+			t.Errorf("Expected shortFile(%q) to be '%s', got '%s'", input, expected, got)
+		}
+	}
+}
+
+func TestSourceAttr(t *testing.T) {
+	t.Run("should return empty attr when AddSource is false", func(t *testing.T) {
+		a := sourceAttr(HandlerOptions{}, 1)
+		if !a.Equal(slog.Attr{}) {
+			t.Errorf("Expected empty attr, got '%v'", a)
+		}
+	})
+
+	t.Run("should pass source through ReplaceAttr", func(t *testing.T) {
+		var groups []string
+		opts := HandlerOptions{
+			AddSource: true,
+			ReplaceAttr: func(gs []string, a slog.Attr) slog.Attr {
+				groups = gs
+				if a.Key == slog.SourceKey {
+					return slog.String("src", "replaced")
+				}
+				return a
+			},
+		}
+
+		a := sourceAttr(opts, callerPC())
+		if a.Key != "src" || a.Value.String() != "replaced" {
+			t.Errorf("Expected replaced source attr, got '%v'", a)
+		}
+		if groups != nil {
+			t.Errorf("Expected nil groups for source attr, got '%v'", groups)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package slogja
+
+import (
+	"reflect"
+	"strings"
+)
+
+// redacted replaces the value of a struct field tagged with redact.
+const redacted = "[REDACTED]"
+
+// fieldTag holds the options of a struct field tag of the form
+//
+//	`slogja:"name,omitempty,redact,inline"`
+//
+// A tag of "-" skips the field.
+type fieldTag struct {
+	name      string
+	omitEmpty bool
+	redact    bool
+	inline    bool
+	skip      bool
+}
+
+// parseFieldTag reads the slogja tag of f, or its json tag when jsonTags is
+// set and f has no slogja tag.
+func parseFieldTag(f reflect.StructField, jsonTags bool) fieldTag {
+	tag, ok := f.Tag.Lookup("slogja")
+	if !ok && jsonTags {
+		tag = f.Tag.Get("json")
+	}
+
+	ft := fieldTag{name: f.Name}
+	if tag == "-" {
+		ft.skip = true
+		return ft
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name != "" {
+		ft.name = name
+	}
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		switch opt {
+		case "omitempty":
+			ft.omitEmpty = true
+		case "redact":
+			ft.redact = true
+		case "inline":
+			ft.inline = true
+		}
+	}
+	return ft
+}
+
+// isEmptyValue reports whether v is empty in the sense of the omitempty
+// option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
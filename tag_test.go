@@ -0,0 +1,163 @@
+package slogja
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type tagAddress struct {
+	City string `slogja:"city"`
+	Zip  string `slogja:"zip,omitempty"`
+}
+
+type tagUser struct {
+	ID       int        `slogja:"id"`
+	Name     string     `slogja:"name"`
+	Password string     `slogja:"password,redact"`
+	Internal string     `slogja:"-"`
+	Email    string     `slogja:",omitempty"`
+	Address  tagAddress `slogja:",inline"`
+}
+
+type tagNode struct {
+	A int
+	N *tagNode `slogja:",inline"`
+}
+
+type tagCreds struct {
+	User     string
+	Password string
+}
+
+func (c tagCreds) LogValue() slog.Value {
+	return slog.StringValue("***")
+}
+
+type jsonUser struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Secret string `json:"-"`
+	Token  string `json:"token" slogja:"token,redact"`
+}
+
+func TestParseFieldTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      reflect.StructTag
+		jsonTags bool
+		expected fieldTag
+	}{
+		{name: "should use field name without tag", expected: fieldTag{name: "Field"}},
+		{name: "should rename", tag: `slogja:"field"`, expected: fieldTag{name: "field"}},
+		{name: "should keep field name with options only", tag: `slogja:",omitempty,redact,inline"`, expected: fieldTag{name: "Field", omitEmpty: true, redact: true, inline: true}},
+		{name: "should skip", tag: `slogja:"-"`, expected: fieldTag{name: "Field", skip: true}},
+		{name: "should name field dash", tag: `slogja:"-,"`, expected: fieldTag{name: "-"}},
+		{name: "should ignore json tag by default", tag: `json:"field"`, expected: fieldTag{name: "Field"}},
+		{name: "should fall back to json tag", tag: `json:"field,omitempty"`, jsonTags: true, expected: fieldTag{name: "field", omitEmpty: true}},
+		{name: "should prefer slogja tag", tag: `json:"field" slogja:"other"`, jsonTags: true, expected: fieldTag{name: "other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseFieldTag(reflect.StructField{Name: "Field", Tag: tt.tag}, tt.jsonTags)
+			if got != tt.expected {
+				t.Errorf("Expected '%+v', got '%+v'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteAnyStructTags(t *testing.T) {
+	user := tagUser{ID: 1, Name: "alice", Password: "hunter2", Internal: "x", Address: tagAddress{City: "Bangkok"}}
+
+	tests := []struct {
+		name  string
+		opt   HandlerOptions
+		value any
+		text  string
+		json  string
+	}{
+		{
+			name:  "should apply slogja tags",
+			value: user,
+			text:  `{id:1 name:"alice" password:"[REDACTED]" city:"Bangkok"}`,
+			json:  `{"id":1,"name":"alice","password":"[REDACTED]","city":"Bangkok"}`,
+		},
+		{
+			name: "should inline pointer and skip nil pointer",
+			value: struct {
+				A *tagAddress `slogja:",inline"`
+				B *tagAddress `slogja:",inline"`
+				C int
+			}{A: &tagAddress{City: "Paris", Zip: "75001"}},
+			text: `{city:"Paris" zip:"75001" C:0}`,
+			json: `{"city":"Paris","zip":"75001","C":0}`,
+		},
+		{
+			name:  "should write inline pointer that contains itself as a field",
+			value: selfNode(),
+			text:  `{A:1 N:<cycle>}`,
+			json:  `{"A":1,"N":"<cycle>"}`,
+		},
+		{
+			name: "should not inline a field written by its own encoder",
+			value: struct {
+				C tagCreds   `slogja:"creds,inline"`
+				T time.Time  `slogja:",inline"`
+				R tagAddress `slogja:"addr,redact,inline"`
+			}{C: tagCreds{User: "u", Password: "hunter2"}, T: time.Unix(0, 0).UTC(), R: tagAddress{City: "Paris"}},
+			text: `{creds:"***" T:1970-01-01T00:00:00.000Z addr:"[REDACTED]"}`,
+			json: `{"creds":"***","T":"1970-01-01T00:00:00.000Z","addr":"[REDACTED]"}`,
+		},
+		{
+			name: "should not start with separator when first field is omitted",
+			value: struct {
+				A string `slogja:"a,omitempty"`
+				B string `slogja:"b"`
+			}{B: "b"},
+			text: `{b:"b"}`,
+			json: `{"b":"b"}`,
+		},
+		{
+			name:  "should ignore json tags by default",
+			value: jsonUser{ID: 1, Secret: "s", Token: "t"},
+			text:  `{ID:1 Name:"" Secret:"s" token:"[REDACTED]"}`,
+			json:  `{"ID":1,"Name":"","Secret":"s","token":"[REDACTED]"}`,
+		},
+		{
+			name:  "should fall back to json tags",
+			opt:   HandlerOptions{JSONTags: true},
+			value: jsonUser{ID: 1, Secret: "s", Token: "t"},
+			text:  `{id:1 token:"[REDACTED]"}`,
+			json:  `{"id":1,"token":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer()
+			defer buf.Free()
+
+			opt := tt.opt
+			opt.DisableColor = true
+			newEncodeText(opt).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.text {
+				t.Errorf("Expected text to contain '%s', got '%s'", tt.text, string(*buf))
+			}
+
+			*buf = (*buf)[:0]
+			newEncodeJSON(tt.opt).writeAny(buf, reflect.ValueOf(tt.value))
+			if string(*buf) != tt.json {
+				t.Errorf("Expected json to contain '%s', got '%s'", tt.json, string(*buf))
+			}
+		})
+	}
+}
+
+func selfNode() *tagNode {
+	n := &tagNode{A: 1}
+	n.N = n
+	return n
+}
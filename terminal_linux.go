@@ -0,0 +1,24 @@
+package slogja
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func isTerminalFile(f *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
+
+// terminalWidth returns the number of columns of the terminal f is attached
+// to, or 0 if it is not a terminal.
+func terminalWidth(f *os.File) int {
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
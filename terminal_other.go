@@ -0,0 +1,21 @@
+//go:build !linux
+
+package slogja
+
+import "os"
+
+// isTerminalFile falls back to treating character devices as terminals on
+// platforms without a dedicated check.
+func isTerminalFile(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth is not available on platforms without a dedicated check,
+// which fall back to COLUMNS.
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,185 @@
+package slogja
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+const (
+	txtGray    = "\033[90m"
+	txtRed     = "\033[31m"
+	txtGreen   = "\033[32m"
+	txtYellow  = "\033[33m"
+	txtBlue    = "\033[34m"
+	txtMagenta = "\033[35m"
+	txtCyan    = "\033[36m"
+	txtWhite   = "\033[37m"
+
+	txtBrightRed    = "\033[91m"
+	txtBrightGreen  = "\033[92m"
+	txtBrightYellow = "\033[93m"
+	txtBrightCyan   = "\033[96m"
+	txtBrightWhite  = "\033[97m"
+
+	txtBold      = "\033[1m"
+	txtUnderline = "\033[4m"
+	txtReset     = "\033[0m"
+)
+
+// Theme holds the ANSI escape sequences used for each segment of a text
+// line. An empty string leaves the segment uncolored. Sequences may be
+// concatenated, e.g. Bold(Color256(208)).
+type Theme struct {
+	// Time colors the record time.
+	Time string
+	// Debug, Info, Warn and Error color the level label. A custom level
+	// without its own LevelStyle.Color uses the color of the standard level
+	// at or below it.
+	Debug string
+	Info  string
+	Warn  string
+	Error string
+	// Message colors the record message.
+	Message string
+	// Key colors attribute keys and Separator the '=' after them, the
+	// arrows of error chains and stack frames.
+	Key       string
+	Separator string
+	// String, Number, Bool, Nil, Duration and TimeValue color attribute
+	// values by kind.
+	String    string
+	Number    string
+	Bool      string
+	Nil       string
+	Duration  string
+	TimeValue string
+	// ErrorValue colors error messages.
+	ErrorValue string
+	// Source colors the source location.
+	Source string
+	// Trace colors the trace and span IDs.
+	Trace string
+}
+
+// levelColor returns the theme color of the standard level at or below
+// level.
+func (t *Theme) levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return t.Error
+	case level >= slog.LevelWarn:
+		return t.Warn
+	case level >= slog.LevelInfo:
+		return t.Info
+	default:
+		return t.Debug
+	}
+}
+
+// Color256 returns the escape sequence for foreground color n of the
+// 256-color palette.
+func Color256(n uint8) string {
+	return "\033[38;5;" + strconv.Itoa(int(n)) + "m"
+}
+
+// ColorRGB returns the escape sequence for a 24-bit truecolor foreground.
+func ColorRGB(r, g, b uint8) string {
+	return "\033[38;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b)) + "m"
+}
+
+// Bold returns color with bold text added.
+func Bold(color string) string {
+	return txtBold + color
+}
+
+// DefaultTheme returns the theme used when HandlerOptions.Theme is nil. It
+// only uses the 8 basic colors.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Time:       txtGray,
+		Info:       txtGreen,
+		Warn:       txtYellow,
+		Error:      txtRed,
+		Message:    txtBold,
+		Key:        txtCyan,
+		Separator:  txtGray,
+		ErrorValue: txtRed,
+		Source:     txtMagenta,
+		Trace:      txtGray,
+	}
+}
+
+// DarkTheme returns a 256-color theme tuned for dark backgrounds.
+func DarkTheme() *Theme {
+	return &Theme{
+		Time:       Color256(244),
+		Debug:      Color256(104),
+		Info:       Color256(114),
+		Warn:       Color256(221),
+		Error:      Color256(203),
+		Message:    Bold(Color256(255)),
+		Key:        Color256(81),
+		Separator:  Color256(240),
+		String:     Color256(180),
+		Number:     Color256(141),
+		Bool:       Color256(215),
+		Nil:        Color256(244),
+		Duration:   Color256(150),
+		TimeValue:  Color256(110),
+		ErrorValue: Color256(203),
+		Source:     Color256(139),
+		Trace:      Color256(240),
+	}
+}
+
+// LightTheme returns a 256-color theme tuned for light backgrounds.
+func LightTheme() *Theme {
+	return &Theme{
+		Time:       Color256(242),
+		Debug:      Color256(61),
+		Info:       Color256(28),
+		Warn:       Color256(130),
+		Error:      Color256(160),
+		Message:    Bold(Color256(232)),
+		Key:        Color256(25),
+		Separator:  Color256(245),
+		String:     Color256(94),
+		Number:     Color256(90),
+		Bool:       Color256(166),
+		Nil:        Color256(242),
+		Duration:   Color256(30),
+		TimeValue:  Color256(31),
+		ErrorValue: Color256(160),
+		Source:     Color256(96),
+		Trace:      Color256(248),
+	}
+}
+
+// MonochromeTheme returns a theme without colors that marks the message,
+// warnings and errors with bold and underlined text only.
+func MonochromeTheme() *Theme {
+	return &Theme{
+		Warn:       txtBold,
+		Error:      txtBold + txtUnderline,
+		Message:    txtBold,
+		ErrorValue: txtBold,
+	}
+}
+
+// HighContrastTheme returns a theme of bold, bright colors for low vision
+// and low quality displays.
+func HighContrastTheme() *Theme {
+	return &Theme{
+		Time:       txtBrightWhite,
+		Debug:      Bold(txtBrightWhite),
+		Info:       Bold(txtBrightGreen),
+		Warn:       Bold(txtBrightYellow),
+		Error:      Bold(txtBrightRed),
+		Message:    Bold(txtBrightWhite),
+		Key:        Bold(txtBrightCyan),
+		Separator:  txtBrightWhite,
+		ErrorValue: Bold(txtBrightRed),
+		Source:     txtBrightWhite,
+		Trace:      txtBrightWhite,
+	}
+}
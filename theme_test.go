@@ -0,0 +1,135 @@
+package slogja
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestColorHelpers(t *testing.T) {
+	if got := Color256(208); got != "\033[38;5;208m" {
+		t.Errorf("Expected 256-color sequence, got %q", got)
+	}
+	if got := ColorRGB(255, 128, 0); got != "\033[38;2;255;128;0m" {
+		t.Errorf("Expected truecolor sequence, got %q", got)
+	}
+	if got := Bold(txtRed); got != txtBold+txtRed {
+		t.Errorf("Expected bold red sequence, got %q", got)
+	}
+}
+
+func TestThemeLevelColor(t *testing.T) {
+	theme := &Theme{Debug: "d", Info: "i", Warn: "w", Error: "e"}
+
+	tests := []struct {
+		level    slog.Level
+		expected string
+	}{
+		{level: slog.LevelDebug - 4, expected: "d"},
+		{level: slog.LevelInfo, expected: "i"},
+		{level: slog.LevelInfo + 2, expected: "i"},
+		{level: slog.LevelWarn, expected: "w"},
+		{level: slog.LevelError + 4, expected: "e"},
+	}
+
+	for _, tt := range tests {
+		if got := theme.levelColor(tt.level); got != tt.expected {
+			t.Errorf("Expected color of level %d to be '%s', got '%s'", tt.level, tt.expected, got)
+		}
+	}
+}
+
+func TestEncodeTheme(t *testing.T) {
+	t.Run("should color values by kind", func(t *testing.T) {
+		theme := &Theme{String: "<s>", Number: "<n>", Bool: "<b>", Nil: "<nil>", Duration: "<d>", TimeValue: "<t>"}
+		encoder := newEncodeText(HandlerOptions{Theme: theme})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeRawValue(buf, slog.StringValue("s"))
+		encoder.writeRawValue(buf, slog.IntValue(1))
+		encoder.writeRawValue(buf, slog.BoolValue(true))
+		encoder.writeRawValue(buf, slog.AnyValue(nil))
+		encoder.writeRawValue(buf, slog.DurationValue(1))
+		encoder.writeRawValue(buf, slog.TimeValue(time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)))
+		expected := `<s>"s"` + txtReset + "<n>1" + txtReset + "<b>true" + txtReset + "<nil>nil" + txtReset +
+			"<d>1" + txtReset + "<t>2023-10-01T12:00:00.000Z" + txtReset
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should color numbers inside slices", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{Theme: DarkTheme()})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeValue(buf, slog.AnyValue([]int{1}))
+		expected := "[" + Color256(141) + "1" + txtReset + "] "
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should use theme color for level without own color", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{Theme: &Theme{Debug: "<dbg>"}, Levels: customLevels})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeLevel(buf, slog.LevelDebug)
+		expected := "<dbg>DBG " + txtReset
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+
+	t.Run("should keep custom level color over theme", func(t *testing.T) {
+		encoder := newEncodeText(HandlerOptions{Theme: HighContrastTheme(), Levels: customLevels})
+		buf := newBuffer()
+		defer buf.Free()
+
+		encoder.writeLevel(buf, levelNotice)
+		expected := txtCyan + "NTC " + txtReset
+		if string(*buf) != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, string(*buf))
+		}
+	})
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for name, theme := range map[string]*Theme{
+		"default":       DefaultTheme(),
+		"dark":          DarkTheme(),
+		"light":         LightTheme(),
+		"monochrome":    MonochromeTheme(),
+		"high-contrast": HighContrastTheme(),
+	} {
+		t.Run("should render a line with "+name+" theme", func(t *testing.T) {
+			b := bytes.NewBuffer([]byte{})
+			h := NewTextHandler(b, &HandlerOptions{Theme: theme, DisableEmoji: true, TimeFormat: time.Kitchen})
+
+			rec := slog.NewRecord(time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC), slog.LevelError, "msg", 0)
+			rec.AddAttrs(slog.String("key", "value"), slog.Int("n", 1))
+			if err := h.Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			plain := stripANSI(b.String())
+			expected := `12:00PM ERR "msg" key="value" n=1 ` + "\n"
+			if plain != expected {
+				t.Errorf("Expected plain text '%s', got '%s'", expected, plain)
+			}
+		})
+	}
+
+	t.Run("should not use colors in monochrome theme", func(t *testing.T) {
+		theme := MonochromeTheme()
+		for _, color := range []string{theme.Time, theme.Info, theme.Key, theme.String, theme.Number, theme.Source} {
+			if color != "" {
+				t.Errorf("Expected no color in monochrome theme, got %q", color)
+			}
+		}
+	})
+}
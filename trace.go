@@ -0,0 +1,67 @@
+package slogja
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+)
+
+// Keys of the trace attributes. They are passed to ReplaceAttr with no
+// groups.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// TraceContext is the W3C trace context of the span active when a record is
+// logged.
+type TraceContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+}
+
+// IsValid reports whether tc has non-zero trace and span IDs.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+// TraceProvider reads the trace context from the context passed to Handle.
+// Implementing it for OpenTelemetry takes a few lines around
+// trace.SpanContextFromContext without this package depending on the SDK.
+type TraceProvider interface {
+	TraceContext(ctx context.Context) (TraceContext, bool)
+}
+
+// TraceFunc adapts a function to a TraceProvider.
+type TraceFunc func(ctx context.Context) (TraceContext, bool)
+
+func (f TraceFunc) TraceContext(ctx context.Context) (TraceContext, bool) {
+	return f(ctx)
+}
+
+// traceAttrs returns the trace attributes for ctx, passed through
+// ReplaceAttr, or nil when there is no valid trace context.
+func traceAttrs(opts HandlerOptions, ctx context.Context) []slog.Attr {
+	if opts.Trace == nil || ctx == nil {
+		return nil
+	}
+
+	tc, ok := opts.Trace.TraceContext(ctx)
+	if !ok || !tc.IsValid() {
+		return nil
+	}
+
+	attrs := []slog.Attr{
+		slog.String(TraceIDKey, hex.EncodeToString(tc.TraceID[:])),
+		slog.String(SpanIDKey, hex.EncodeToString(tc.SpanID[:])),
+		slog.String(TraceFlagsKey, hex.EncodeToString([]byte{tc.TraceFlags})),
+	}
+	if rep := opts.ReplaceAttr; rep != nil {
+		for i := range attrs {
+			attrs[i] = rep(nil, attrs[i])
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,138 @@
+package slogja
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+// testSpan stands in for a tracing span stored in the context.
+type testSpan struct {
+	tc TraceContext
+}
+
+type testSpanKey struct{}
+
+func testTraceProvider(ctx context.Context) (TraceContext, bool) {
+	span, ok := ctx.Value(testSpanKey{}).(*testSpan)
+	if !ok {
+		return TraceContext{}, false
+	}
+	return span.tc, true
+}
+
+func testSpanContext() context.Context {
+	span := &testSpan{tc: TraceContext{
+		TraceID:    [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: 1,
+	}}
+	return context.WithValue(context.Background(), testSpanKey{}, span)
+}
+
+func TestTraceContextIsValid(t *testing.T) {
+	if (TraceContext{}).IsValid() {
+		t.Errorf("Expected zero trace context to be invalid")
+	}
+	if (TraceContext{TraceID: [16]byte{1}}).IsValid() {
+		t.Errorf("Expected trace context without span ID to be invalid")
+	}
+	if !(TraceContext{TraceID: [16]byte{1}, SpanID: [8]byte{1}}).IsValid() {
+		t.Errorf("Expected trace context with trace and span IDs to be valid")
+	}
+}
+
+func TestHandlerTrace(t *testing.T) {
+	ctx := testSpanContext()
+
+	t.Run("should write shortened IDs in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			DisableColor: true,
+			DisableEmoji: true,
+			DisableTime:  true,
+			Trace:        TraceFunc(testTraceProvider),
+		}))
+
+		l.InfoContext(ctx, "msg", "key", "value")
+
+		expected := `INF "msg" trace_id=4bf92f35 span_id=00f067aa key="value" ` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should dim IDs in text handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewTextHandler(b, &HandlerOptions{
+			Color:        ColorAlways,
+			DisableEmoji: true,
+			DisableTime:  true,
+			DisableLevel: true,
+			Theme:        &Theme{Trace: txtGray},
+			Trace:        TraceFunc(testTraceProvider),
+		}))
+
+		l.InfoContext(ctx, "msg")
+
+		expected := `"msg" ` + txtGray + `trace_id=4bf92f35` + txtReset + ` ` + txtGray + `span_id=00f067aa` + txtReset + " \n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%q', got '%q'", expected, b.String())
+		}
+	})
+
+	t.Run("should write full fields in json handler", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, &HandlerOptions{
+			DisableTime: true,
+			Trace:       TraceFunc(testTraceProvider),
+		}))
+
+		l.WithGroup("g").InfoContext(ctx, "msg", "key", "value")
+
+		expected := `{"level":"INFO","msg":"msg","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01","g":{"key":"value"}}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should pass trace attributes to ReplaceAttr", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, &HandlerOptions{
+			DisableTime: true,
+			Trace:       TraceFunc(testTraceProvider),
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == TraceFlagsKey {
+					return slog.Attr{}
+				}
+				if a.Key == TraceIDKey {
+					a.Key = "trace"
+				}
+				return a
+			},
+		}))
+
+		l.InfoContext(ctx, "msg")
+
+		expected := `{"level":"INFO","msg":"msg","trace":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("should write nothing without a span", func(t *testing.T) {
+		b := bytes.NewBuffer([]byte{})
+		l := slog.New(NewJSONHandler(b, &HandlerOptions{
+			DisableTime: true,
+			Trace:       TraceFunc(testTraceProvider),
+		}))
+
+		l.InfoContext(context.Background(), "msg")
+
+		expected := `{"level":"INFO","msg":"msg"}` + "\n"
+		if b.String() != expected {
+			t.Errorf("Expected buffer to contain '%s', got '%s'", expected, b.String())
+		}
+	})
+}
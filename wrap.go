@@ -0,0 +1,185 @@
+package slogja
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WrapMode selects what the text handler does with lines wider than the
+// terminal.
+type WrapMode int
+
+const (
+	// WrapOff leaves long lines to the terminal.
+	WrapOff WrapMode = iota
+	// WrapAttrs breaks long lines between attributes and indents the
+	// following lines, so that no key or value is split.
+	WrapAttrs
+	// WrapTruncate cuts long lines and ends them with "…".
+	WrapTruncate
+)
+
+const (
+	defaultLineWidth = 80
+	wrapIndent       = 4
+)
+
+// lineWidth resolves the width used by the WrapMode of opts for w: Width if
+// set, else COLUMNS, else the size of the terminal w is attached to, else
+// 80.
+func lineWidth(w io.Writer, opts HandlerOptions) int {
+	if opts.Width > 0 {
+		return opts.Width
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if f, ok := w.(*os.File); ok {
+		if n := terminalWidth(f); n > 0 {
+			return n
+		}
+	}
+	return defaultLineWidth
+}
+
+// fitLine applies the WrapMode to the line in buf, whose attributes start
+// at mark.
+func (e *encodeText) fitLine(buf *buffer, mark int) {
+	switch e.opt.Wrap {
+	case WrapAttrs:
+		e.wrapAttrs(buf, mark)
+	case WrapTruncate:
+		e.truncateLines(buf)
+	}
+}
+
+// wrapAttrs breaks the attributes written to buf since mark onto new lines
+// where they would go past Width. The following lines are indented to the
+// attribute column in LayoutColumns and by four spaces otherwise.
+func (e *encodeText) wrapAttrs(buf *buffer, mark int) {
+	width := e.opt.Width
+	s := string((*buf)[mark:])
+	head := string((*buf)[:mark])
+
+	col := stringWidth(head[strings.LastIndexByte(head, '\n')+1:])
+	indent := wrapIndent
+	if e.opt.Layout == LayoutColumns && col < width/2 {
+		indent = col
+	}
+
+	*buf = (*buf)[:mark]
+	for i := 0; i < len(s); {
+		end := attrEnd(s, i)
+		attr := s[i:end]
+		w := stringWidth(strings.TrimRight(attr, " "))
+
+		if col > indent && col+w > width {
+			for n := len(*buf); n > 0 && (*buf)[n-1] == ' '; n-- {
+				*buf = (*buf)[:n-1]
+			}
+			e.writeNewline(buf)
+			buf.WriteString(strings.Repeat(" ", indent))
+			col = indent
+		}
+
+		buf.WriteString(attr)
+		col += stringWidth(attr)
+		if end < len(s) {
+			e.writeSpace(buf)
+			col++
+			end++
+		}
+		i = end
+	}
+}
+
+// attrEnd returns the offset of the space that ends the attribute starting
+// at offset i of s, or len(s). Spaces in quoted strings, between brackets
+// or not followed by a key and '=' do not end an attribute.
+func attrEnd(s string, i int) int {
+	depth, quoted := 0, false
+	for i < len(s) {
+		if n := ansiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+
+		switch c := s[i]; {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth = max(depth-1, 0)
+		case c == ' ' && depth == 0 && startsAttr(s[i+1:]):
+			return i
+		}
+		i++
+	}
+	return len(s)
+}
+
+// startsAttr reports whether s starts with a key, plain or quoted, followed
+// by '='.
+func startsAttr(s string) bool {
+	i := skipANSI(s, 0)
+	if i < len(s) && s[i] == '"' {
+		for i++; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		i++
+	} else {
+		start := i
+		for i < len(s) && s[i] != ' ' && s[i] != '=' && s[i] != '"' && ansiLen(s[i:]) == 0 {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+	i = skipANSI(s, i)
+	return i < len(s) && s[i] == '='
+}
+
+// skipANSI returns the offset of the first byte at or after i of s that is
+// not part of an escape sequence.
+func skipANSI(s string, i int) int {
+	for i < len(s) {
+		n := ansiLen(s[i:])
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	return i
+}
+
+// truncateLines cuts each line in buf to Width cells, ending cut lines with
+// "…".
+func (e *encodeText) truncateLines(buf *buffer) {
+	width := e.opt.Width
+	s := string(*buf)
+
+	*buf = (*buf)[:0]
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			e.writeNewline(buf)
+		}
+		plain := strings.TrimRight(stripANSI(line), " ")
+		if stringWidth(plain) <= width {
+			buf.WriteString(line)
+			continue
+		}
+		*buf = appendVisible(*buf, line, 0, cutWidth(plain, width-1), "…")
+	}
+}
@@ -0,0 +1,124 @@
+package slogja
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLineWidth(t *testing.T) {
+	t.Run("should prefer Width", func(t *testing.T) {
+		t.Setenv("COLUMNS", "100")
+		if got := lineWidth(&bytes.Buffer{}, HandlerOptions{Width: 60}); got != 60 {
+			t.Errorf("Expected 60, got %d", got)
+		}
+	})
+
+	t.Run("should read COLUMNS", func(t *testing.T) {
+		t.Setenv("COLUMNS", "100")
+		if got := lineWidth(&bytes.Buffer{}, HandlerOptions{}); got != 100 {
+			t.Errorf("Expected 100, got %d", got)
+		}
+	})
+
+	t.Run("should default to 80", func(t *testing.T) {
+		t.Setenv("COLUMNS", "")
+		if got := lineWidth(&bytes.Buffer{}, HandlerOptions{}); got != 80 {
+			t.Errorf("Expected 80, got %d", got)
+		}
+	})
+
+	t.Run("should not find the width of a regular file", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "log")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		if got := terminalWidth(f); got != 0 {
+			t.Errorf("Expected 0, got %d", got)
+		}
+	})
+}
+
+func TestHandlerWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		opt      HandlerOptions
+		args     []any
+		expected string
+	}{
+		{
+			name: "should break between attributes",
+			opt:  HandlerOptions{Wrap: WrapAttrs, Width: 24},
+			args: []any{"a", 1, "bb", "two", "ccc", 3},
+			expected: `INF "msg" a=1 bb="two"` + "\n" +
+				`    ccc=3 ` + "\n",
+		},
+		{
+			name: "should not split quoted strings and collections",
+			opt:  HandlerOptions{Wrap: WrapAttrs, Width: 20},
+			args: []any{"s", "x y=z", "l", []int{1, 2, 3, 4}, "m", map[string]int{"k": 1}},
+			expected: `INF "msg" s="x y=z"` + "\n" +
+				`    l=[1 2 3 4]` + "\n" +
+				`    m=["k":1] ` + "\n",
+		},
+		{
+			name: "should measure wide characters",
+			opt:  HandlerOptions{Wrap: WrapAttrs, Width: 18, Quote: QuoteAuto},
+			args: []any{"a", "日本語", "b", 1},
+			expected: `INF msg a=日本語` + "\n" +
+				`    b=1 ` + "\n",
+		},
+		{
+			name: "should indent to the attribute column",
+			opt:  HandlerOptions{Wrap: WrapAttrs, Width: 24, Layout: LayoutColumns, MessageWidth: 5},
+			args: []any{"a", 1, "b", 2, "c", 3, "d", 4},
+			expected: `INF "msg" a=1 b=2 c=3` + "\n" +
+				`          d=4 ` + "\n",
+		},
+		{
+			name:     "should keep a line that fits",
+			opt:      HandlerOptions{Wrap: WrapAttrs, Width: 80},
+			args:     []any{"a", 1},
+			expected: `INF "msg" a=1 ` + "\n",
+		},
+		{
+			name:     "should truncate long lines",
+			opt:      HandlerOptions{Wrap: WrapTruncate, Width: 16},
+			args:     []any{"a", 1, "b", "long value"},
+			expected: `INF "msg" a=1 b…` + "\n",
+		},
+		{
+			name:     "should reset colors when truncating",
+			opt:      HandlerOptions{Color: ColorAlways, Wrap: WrapTruncate, Width: 12, Theme: &Theme{Key: txtCyan}},
+			args:     []any{"a", 1, "b", 2},
+			expected: `INF "msg" ` + txtCyan + `a…` + txtReset + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			tt.opt.DisableEmoji = true
+			tt.opt.DisableTime = true
+			if tt.opt.Theme == nil {
+				tt.opt.DisableColor = true
+			}
+			h := NewTextHandler(b, &tt.opt)
+
+			rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			rec.Add(tt.args...)
+			if err := h.Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if b.String() != tt.expected {
+				t.Errorf("Expected:\n%q\ngot:\n%q", tt.expected, b.String())
+			}
+		})
+	}
+}